@@ -0,0 +1,10 @@
+package apt
+
+import "errors"
+
+var (
+	// ErrInvalidPackage is an invalid package error.
+	ErrInvalidPackage = errors.New("invalid package")
+	// ErrUnsupportedLayout is an unsupported repository layout error.
+	ErrUnsupportedLayout = errors.New("unsupported layout")
+)
@@ -0,0 +1,449 @@
+// Package apt provides APT repositories management.
+package apt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ulikunitz/xz"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/clearsign"
+	"mkdeb.sh/deb"
+)
+
+// Layouts:
+const (
+	LayoutFlat = "flat"
+	LayoutPool = "pool"
+)
+
+// Repository is an APT repository.
+type Repository struct {
+	Path      string
+	Layout    string
+	Suite     string
+	Component string
+	Origin    string
+	Label     string
+	Date      time.Time
+	Signer    *deb.OpenPGPSigner
+}
+
+// NewRepository creates a new APT repository instance given a base path and a layout.
+func NewRepository(path, layout string) (*Repository, error) {
+	if layout != LayoutFlat && layout != LayoutPool {
+		return nil, ErrUnsupportedLayout
+	}
+
+	return &Repository{
+		Path:      path,
+		Layout:    layout,
+		Suite:     "stable",
+		Component: "main",
+	}, nil
+}
+
+// Add copies a Debian package into the repository, returning its path relative to the repository root.
+//
+// Repository indexes must be regenerated using Update once packages have been added.
+func (r *Repository) Add(path string) (string, error) {
+	pkg, err := deb.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read package: %w", err)
+	}
+
+	name := pkg.Control.Name
+	if name == "" {
+		return "", fmt.Errorf("%w: missing package name", ErrInvalidPackage)
+	}
+
+	rel := filepath.Base(path)
+	if r.Layout == LayoutPool {
+		rel = filepath.Join("pool", r.Component, poolPrefix(name), name, rel)
+	}
+
+	dst := filepath.Join(r.Path, rel)
+
+	err = os.MkdirAll(filepath.Dir(dst), 0755)
+	if err != nil {
+		return "", err
+	}
+
+	err = copyFile(path, dst)
+	if err != nil {
+		return "", err
+	}
+
+	return rel, nil
+}
+
+// Update regenerates the repository indexes and release files.
+//
+// It returns the list of index files paths (relative to the repository root) that have been written.
+func (r *Repository) Update() ([]string, error) {
+	var (
+		base    string
+		written []string
+	)
+
+	entries, err := r.scan()
+	if err != nil {
+		return nil, err
+	}
+
+	// Group packages per architecture, "all" packages being part of every architecture index
+	archs := map[string][]*entry{}
+	for _, e := range entries {
+		if e.arch != "all" {
+			archs[e.arch] = nil
+		}
+	}
+	if len(archs) == 0 {
+		archs["all"] = nil
+	}
+
+	for arch := range archs {
+		for _, e := range entries {
+			if e.arch == arch || e.arch == "all" {
+				archs[arch] = append(archs[arch], e)
+			}
+		}
+	}
+
+	archList := []string{}
+	for arch := range archs {
+		archList = append(archList, arch)
+	}
+	sort.Strings(archList)
+
+	switch r.Layout {
+	case LayoutFlat:
+		base = r.Path
+
+		// Flat repositories only provide a single index
+		written, err = writeIndex(base, "", entries)
+		if err != nil {
+			return nil, err
+		}
+
+	case LayoutPool:
+		base = filepath.Join(r.Path, "dists", r.Suite)
+
+		for _, arch := range archList {
+			files, err := writeIndex(base, filepath.Join(r.Component, "binary-"+arch), archs[arch])
+			if err != nil {
+				return nil, err
+			}
+			written = append(written, files...)
+		}
+
+	default:
+		return nil, ErrUnsupportedLayout
+	}
+
+	release, err := r.release(base, archList, written)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate release: %w", err)
+	}
+
+	written = append(written, "Release")
+
+	err = ioutil.WriteFile(filepath.Join(base, "Release"), release, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Signer != nil {
+		err = r.sign(base, release)
+		if err != nil {
+			return nil, fmt.Errorf("cannot sign release: %w", err)
+		}
+
+		written = append(written, "InRelease", "Release.gpg")
+	}
+
+	// Return paths relative to repository root
+	for idx, path := range written {
+		rel, err := filepath.Rel(r.Path, filepath.Join(base, path))
+		if err != nil {
+			return nil, err
+		}
+		written[idx] = rel
+	}
+
+	return written, nil
+}
+
+func (r *Repository) release(base string, archs, files []string) ([]byte, error) {
+	var md5Sums, sha256Sums string
+
+	date := r.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	buf := bytes.NewBuffer(nil)
+
+	if r.Origin != "" {
+		fmt.Fprintf(buf, "Origin: %s\n", r.Origin)
+	}
+	if r.Label != "" {
+		fmt.Fprintf(buf, "Label: %s\n", r.Label)
+	}
+	if r.Layout == LayoutPool {
+		fmt.Fprintf(buf, "Suite: %s\n", r.Suite)
+		fmt.Fprintf(buf, "Codename: %s\n", r.Suite)
+		fmt.Fprintf(buf, "Components: %s\n", r.Component)
+	}
+	fmt.Fprintf(buf, "Date: %s\n", date.UTC().Format(time.RFC1123))
+	fmt.Fprintf(buf, "Architectures: %s\n", strings.Join(archs, " "))
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(filepath.Join(base, file))
+		if err != nil {
+			return nil, err
+		}
+
+		md5Sums += fmt.Sprintf(" %x %d %s\n", md5.Sum(data), len(data), filepath.ToSlash(file))
+		sha256Sums += fmt.Sprintf(" %x %d %s\n", sha256.Sum256(data), len(data), filepath.ToSlash(file))
+	}
+
+	fmt.Fprintf(buf, "MD5Sum:\n%s", md5Sums)
+	fmt.Fprintf(buf, "SHA256:\n%s", sha256Sums)
+
+	return buf.Bytes(), nil
+}
+
+func (r *Repository) sign(base string, release []byte) error {
+	// Generate clear-signed release file
+	buf := bytes.NewBuffer(nil)
+
+	w, err := clearsign.Encode(buf, r.Signer.Entity.PrivateKey, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(release)
+	if err != nil {
+		return err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(filepath.Join(base, "InRelease"), buf.Bytes(), 0644)
+	if err != nil {
+		return err
+	}
+
+	// Generate detached release signature
+	buf.Reset()
+
+	err = openpgp.ArmoredDetachSign(buf, r.Signer.Entity, bytes.NewReader(release), nil)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(base, "Release.gpg"), buf.Bytes(), 0644)
+}
+
+func (r *Repository) scan() ([]*entry, error) {
+	root := r.Path
+	if r.Layout == LayoutPool {
+		root = filepath.Join(r.Path, "pool", r.Component)
+	}
+
+	entries := []*entry{}
+
+	err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if os.IsNotExist(err) && path == root {
+			return filepath.SkipDir
+		} else if err != nil {
+			return err
+		}
+
+		if fi.IsDir() {
+			// Flat repositories only hold packages at their root
+			if r.Layout == LayoutFlat && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		} else if filepath.Ext(path) != ".deb" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(r.Path, path)
+		if err != nil {
+			return err
+		}
+
+		e, err := newEntry(path, filepath.ToSlash(rel))
+		if err != nil {
+			return fmt.Errorf("cannot read %q: %w", rel, err)
+		}
+
+		entries = append(entries, e)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].name != entries[j].name {
+			return entries[i].name < entries[j].name
+		} else if cmp := compareVersions(entries[i].version, entries[j].version); cmp != 0 {
+			return cmp < 0
+		}
+		return entries[i].arch < entries[j].arch
+	})
+
+	return entries, nil
+}
+
+type entry struct {
+	name    string
+	version string
+	arch    string
+	data    string
+}
+
+func newEntry(path, rel string) (*entry, error) {
+	pkg, err := deb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	md5Sum, sha256Sum := md5.New(), sha256.New()
+
+	size, err := io.Copy(io.MultiWriter(md5Sum, sha256Sum), f)
+	if err != nil {
+		return nil, err
+	}
+
+	data := strings.TrimRight(string(pkg.ControlData()), "\n") + "\n"
+	data += fmt.Sprintf("Filename: %s\n", rel)
+	data += fmt.Sprintf("Size: %d\n", size)
+	data += fmt.Sprintf("MD5sum: %x\n", md5Sum.Sum(nil))
+	data += fmt.Sprintf("SHA256: %x\n", sha256Sum.Sum(nil))
+
+	return &entry{
+		name:    pkg.Control.Name,
+		version: pkg.Control.Version,
+		arch:    pkg.Control.Architecture,
+		data:    data,
+	}, nil
+}
+
+func writeIndex(base, dir string, entries []*entry) ([]string, error) {
+	var data []string
+
+	for _, e := range entries {
+		data = append(data, e.data)
+	}
+
+	content := []byte(strings.Join(data, "\n"))
+
+	err := os.MkdirAll(filepath.Join(base, dir), 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write plain and compressed variants of the index
+	gz := bytes.NewBuffer(nil)
+	gw := gzip.NewWriter(gz)
+	if _, err = gw.Write(content); err != nil {
+		return nil, err
+	} else if err = gw.Close(); err != nil {
+		return nil, err
+	}
+
+	xzBuf := bytes.NewBuffer(nil)
+	xw, err := xz.NewWriter(xzBuf)
+	if err != nil {
+		return nil, err
+	} else if _, err = xw.Write(content); err != nil {
+		return nil, err
+	} else if err = xw.Close(); err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{
+		"Packages":    content,
+		"Packages.gz": gz.Bytes(),
+		"Packages.xz": xzBuf.Bytes(),
+	}
+
+	written := []string{}
+	for _, name := range []string{"Packages", "Packages.gz", "Packages.xz"} {
+		path := filepath.Join(dir, name)
+
+		err = ioutil.WriteFile(filepath.Join(base, path), files[name], 0644)
+		if err != nil {
+			return nil, err
+		}
+
+		written = append(written, path)
+	}
+
+	return written, nil
+}
+
+// compareVersions compares Debian package versions, falling back to a string comparison if either is invalid.
+func compareVersions(a, b string) int {
+	va, errA := deb.ParseVersion(a)
+	vb, errB := deb.ParseVersion(b)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+
+	return deb.Compare(va, vb)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// poolPrefix returns the pool directory prefix of a package, as used by Debian archives.
+func poolPrefix(name string) string {
+	if strings.HasPrefix(name, "lib") && len(name) > 3 {
+		return name[:4]
+	}
+	return name[:1]
+}
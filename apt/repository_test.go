@@ -0,0 +1,121 @@
+package apt
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/openpgp"
+	"mkdeb.sh/deb"
+)
+
+func TestRepositoryFlat(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mkdeb-apt")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	repo, err := NewRepository(filepath.Join(dir, "repo"), LayoutFlat)
+	assert.Nil(t, err)
+	repo.Origin = "Foo"
+	repo.Date = time.Unix(0, 0)
+
+	for _, arch := range []string{"amd64", "arm64"} {
+		rel, err := repo.Add(writePackage(t, dir, "foo", arch, "1.2.3"))
+		assert.Nil(t, err)
+		assert.Equal(t, "foo_1.2.3-1~mkdeb1_"+arch+".deb", rel)
+	}
+
+	files, err := repo.Update()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Packages", "Packages.gz", "Packages.xz", "Release"}, files)
+
+	packages, err := ioutil.ReadFile(filepath.Join(dir, "repo", "Packages"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(packages), "Package: foo\n")
+	assert.Contains(t, string(packages), "Filename: foo_1.2.3-1~mkdeb1_amd64.deb\n")
+	assert.Contains(t, string(packages), "Filename: foo_1.2.3-1~mkdeb1_arm64.deb\n")
+	assert.Contains(t, string(packages), "\nSHA256: ")
+
+	release, err := ioutil.ReadFile(filepath.Join(dir, "repo", "Release"))
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(string(release), "Origin: Foo\nDate: Thu, 01 Jan 1970 00:00:00 UTC\n"+
+		"Architectures: amd64 arm64\n"))
+	assert.Contains(t, string(release), " Packages.xz\n")
+}
+
+func TestRepositoryPool(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mkdeb-apt")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	entity, err := openpgp.NewEntity("Foo Bar", "", "foo@example.org", nil)
+	assert.Nil(t, err)
+
+	repo, err := NewRepository(filepath.Join(dir, "repo"), LayoutPool)
+	assert.Nil(t, err)
+	repo.Signer = &deb.OpenPGPSigner{Entity: entity}
+
+	rel, err := repo.Add(writePackage(t, dir, "libfoo", "amd64", "1.2.3"))
+	assert.Nil(t, err)
+	assert.Equal(t, "pool/main/libf/libfoo/libfoo_1.2.3-1~mkdeb1_amd64.deb", rel)
+
+	rel, err = repo.Add(writePackage(t, dir, "bar", "all", "0.1.0"))
+	assert.Nil(t, err)
+	assert.Equal(t, "pool/main/b/bar/bar_0.1.0-1~mkdeb1_all.deb", rel)
+
+	files, err := repo.Update()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"dists/stable/main/binary-amd64/Packages",
+		"dists/stable/main/binary-amd64/Packages.gz",
+		"dists/stable/main/binary-amd64/Packages.xz",
+		"dists/stable/Release",
+		"dists/stable/InRelease",
+		"dists/stable/Release.gpg",
+	}, files)
+
+	// Architecture-independent packages are listed in every architecture index
+	packages, err := ioutil.ReadFile(filepath.Join(dir, "repo", "dists/stable/main/binary-amd64/Packages"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(packages), "Filename: pool/main/b/bar/bar_0.1.0-1~mkdeb1_all.deb\n")
+	assert.Contains(t, string(packages), "Filename: pool/main/libf/libfoo/libfoo_1.2.3-1~mkdeb1_amd64.deb\n")
+
+	release, err := ioutil.ReadFile(filepath.Join(dir, "repo", "dists/stable/Release"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(release), "Suite: stable\n")
+	assert.Contains(t, string(release), " main/binary-amd64/Packages\n")
+
+	sig, err := ioutil.ReadFile(filepath.Join(dir, "repo", "dists/stable/Release.gpg"))
+	assert.Nil(t, err)
+
+	_, err = openpgp.CheckArmoredDetachedSignature(openpgp.EntityList{entity}, bytes.NewReader(release),
+		bytes.NewReader(sig))
+	assert.Nil(t, err)
+}
+
+func TestNewRepositoryInvalidLayout(t *testing.T) {
+	_, err := NewRepository("repo", "foo")
+	assert.Equal(t, ErrUnsupportedLayout, err)
+}
+
+func writePackage(t *testing.T, dir, name, arch, version string) string {
+	p, err := deb.NewPackage(name, arch, version, 0, 1)
+	assert.Nil(t, err)
+	p.Control.Description = "Foo package"
+
+	err = p.AddDir("/usr/share/"+name, 0755)
+	assert.Nil(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, p.Write(buf))
+
+	path := filepath.Join(dir, name+"_"+version+"-1~mkdeb1_"+arch+".deb")
+	assert.Nil(t, ioutil.WriteFile(path, buf.Bytes(), 0644))
+
+	return path
+}
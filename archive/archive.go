@@ -10,4 +10,46 @@ const (
 	CompressGzip
 	// CompressXZ is a XZ compression format.
 	CompressXZ
+	// CompressZstd is a Zstandard compression format.
+	CompressZstd
 )
+
+// LevelDefault is the default compression level of a given format.
+const LevelDefault = 0
+
+var compressNames = map[int]string{
+	CompressNone:  "none",
+	CompressBzip2: "bzip2",
+	CompressGzip:  "gzip",
+	CompressXZ:    "xz",
+	CompressZstd:  "zstd",
+}
+
+var compressExts = map[int]string{
+	CompressNone:  "",
+	CompressBzip2: ".bz2",
+	CompressGzip:  ".gz",
+	CompressXZ:    ".xz",
+	CompressZstd:  ".zst",
+}
+
+// CompressExt returns the file extension of a given compression format.
+func CompressExt(compress int) string {
+	return compressExts[compress]
+}
+
+// CompressName returns the name of a given compression format.
+func CompressName(compress int) string {
+	return compressNames[compress]
+}
+
+// ParseCompress returns the compression format matching a given name.
+func ParseCompress(name string) (int, error) {
+	for compress, v := range compressNames {
+		if v == name {
+			return compress, nil
+		}
+	}
+
+	return 0, ErrUnsupportedCompress
+}
@@ -1,5 +1,34 @@
 package archive
 
-import "time"
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
 
 var testTime = time.Date(2018, 3, 18, 10, 8, 0, 0, time.UTC)
+
+func TestParseCompress(t *testing.T) {
+	for name, compress := range map[string]int{
+		"none":  CompressNone,
+		"bzip2": CompressBzip2,
+		"gzip":  CompressGzip,
+		"xz":    CompressXZ,
+		"zstd":  CompressZstd,
+	} {
+		v, err := ParseCompress(name)
+		assert.Nil(t, err)
+		assert.Equal(t, compress, v)
+		assert.Equal(t, name, CompressName(v))
+	}
+
+	_, err := ParseCompress("foo")
+	assert.Equal(t, ErrUnsupportedCompress, err)
+}
+
+func TestCompressExt(t *testing.T) {
+	assert.Equal(t, "", CompressExt(CompressNone))
+	assert.Equal(t, ".gz", CompressExt(CompressGzip))
+	assert.Equal(t, ".zst", CompressExt(CompressZstd))
+}
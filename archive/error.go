@@ -5,4 +5,6 @@ import "errors"
 var (
 	// ErrUnsupportedCompress is an unsupported compression format error.
 	ErrUnsupportedCompress = errors.New("unsupported compression")
+	// ErrUnsupportedLevel is an unsupported compression level error.
+	ErrUnsupportedLevel = errors.New("unsupported compression level")
 )
@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/ioutil"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/ulikunitz/xz"
 )
 
@@ -43,6 +44,13 @@ func NewReader(r io.Reader, compress int) (*Reader, error) {
 		}
 		rc = ioutil.NopCloser(r)
 
+	case CompressZstd:
+		r, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		rc = r.IOReadCloser()
+
 	default:
 		return nil, ErrUnsupportedCompress
 	}
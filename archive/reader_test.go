@@ -36,6 +36,13 @@ func TestReaderXZ(t *testing.T) {
 	testReader(t, f, CompressXZ)
 }
 
+func TestReaderZstd(t *testing.T) {
+	f, err := os.Open("testdata/data.tar.zst")
+	assert.Nil(t, err)
+
+	testReader(t, f, CompressZstd)
+}
+
 func TestReaderUnsupported(t *testing.T) {
 	r, err := NewReader(nil, -1)
 	assert.Nil(t, r)
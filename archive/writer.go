@@ -6,6 +6,7 @@ import (
 	"compress/gzip"
 	"io"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/ulikunitz/xz"
 )
 
@@ -17,22 +18,12 @@ type Writer struct {
 
 // NewWriter creates a new archive writer instance given an io.Writer and a compression format.
 func NewWriter(w io.Writer, compress int) (*Writer, error) {
-	var (
-		cw  io.WriteCloser
-		err error
-	)
-
-	switch compress {
-	case CompressGzip:
-		cw = gzip.NewWriter(w)
-
-	case CompressXZ:
-		cw, err = xz.NewWriter(w)
-
-	default:
-		err = ErrUnsupportedCompress
-	}
+	return NewWriterLevel(w, compress, LevelDefault)
+}
 
+// NewWriterLevel creates a new archive writer instance given an io.Writer, a compression format and level.
+func NewWriterLevel(w io.Writer, compress, level int) (*Writer, error) {
+	cw, err := NewCompressWriter(w, compress, level)
 	if err != nil {
 		return nil, err
 	}
@@ -43,6 +34,58 @@ func NewWriter(w io.Writer, compress int) (*Writer, error) {
 	}, nil
 }
 
+// NewCompressWriter creates a new raw compression writer given an io.Writer, a compression format and level.
+func NewCompressWriter(w io.Writer, compress, level int) (io.WriteCloser, error) {
+	switch compress {
+	case CompressNone:
+		if level != LevelDefault {
+			return nil, ErrUnsupportedLevel
+		}
+
+		return nopWriteCloser{w}, nil
+
+	case CompressGzip:
+		if level == LevelDefault {
+			level = gzip.DefaultCompression
+		} else if level < gzip.BestSpeed || level > gzip.BestCompression {
+			return nil, ErrUnsupportedLevel
+		}
+
+		gw, err := gzip.NewWriterLevel(w, level)
+		if err != nil {
+			return nil, err
+		}
+
+		// Reset header fields to ensure output doesn't depend on the build environment
+		gw.Header = gzip.Header{OS: 255}
+
+		return gw, nil
+
+	case CompressXZ:
+		if level == LevelDefault {
+			return xz.NewWriter(w)
+		} else if level < 1 || level > len(xzDictCaps) {
+			return nil, ErrUnsupportedLevel
+		}
+
+		return xz.WriterConfig{DictCap: xzDictCaps[level-1]}.NewWriter(w)
+
+	case CompressZstd:
+		opts := []zstd.EOption{zstd.WithEncoderConcurrency(1)}
+		if level != LevelDefault {
+			if level < 1 || level > 22 {
+				return nil, ErrUnsupportedLevel
+			}
+
+			opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+		}
+
+		return zstd.NewWriter(w, opts...)
+	}
+
+	return nil, ErrUnsupportedCompress
+}
+
 // Close closes the archive writer.
 func (w *Writer) Close() error {
 	err := w.tar.Close()
@@ -72,9 +115,14 @@ type WriterBuffer struct {
 
 // NewWriterBuffer creates a new archive writer buffer instance given a compression format.
 func NewWriterBuffer(compress int) (*WriterBuffer, error) {
+	return NewWriterBufferLevel(compress, LevelDefault)
+}
+
+// NewWriterBufferLevel creates a new archive writer buffer instance given a compression format and level.
+func NewWriterBufferLevel(compress, level int) (*WriterBuffer, error) {
 	buf := bytes.NewBuffer(nil)
 
-	w, err := NewWriter(buf, compress)
+	w, err := NewWriterLevel(buf, compress, level)
 	if err != nil {
 		return nil, err
 	}
@@ -86,3 +134,14 @@ func NewWriterBuffer(compress int) (*WriterBuffer, error) {
 func (w *WriterBuffer) Bytes() []byte {
 	return w.buffer.Bytes()
 }
+
+// xzDictCaps are the XZ dictionary sizes matching xz(1) compression presets from 1 to 9.
+var xzDictCaps = []int{1 << 20, 2 << 20, 4 << 20, 4 << 20, 8 << 20, 8 << 20, 16 << 20, 32 << 20, 64 << 20}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
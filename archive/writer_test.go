@@ -1,6 +1,8 @@
 package archive
 
 import (
+	"bytes"
+	"io/ioutil"
 	"os"
 	"testing"
 
@@ -15,6 +17,67 @@ func TestWriterXZ(t *testing.T) {
 	testWriter(t, CompressXZ)
 }
 
+func TestWriterNone(t *testing.T) {
+	testWriter(t, CompressNone)
+}
+
+func TestWriterZstd(t *testing.T) {
+	testWriter(t, CompressZstd)
+}
+
+func TestWriterLevel(t *testing.T) {
+	for _, test := range []struct {
+		compress int
+		level    int
+	}{
+		{CompressGzip, 1},
+		{CompressGzip, 9},
+		{CompressXZ, 1},
+		{CompressXZ, 9},
+		{CompressZstd, 1},
+		{CompressZstd, 19},
+	} {
+		w, err := NewWriterBufferLevel(test.compress, test.level)
+		assert.Nil(t, err)
+
+		err = w.WriteHeader(&Header{Name: "file1", Size: 4, Mode: os.FileMode(0644), ModTime: testTime})
+		assert.Nil(t, err)
+		_, err = w.Write([]byte("foo\n"))
+		assert.Nil(t, err)
+		assert.Nil(t, w.Close())
+
+		// Read back archive content
+		r, err := NewReader(bytes.NewReader(w.Bytes()), test.compress)
+		assert.Nil(t, err)
+
+		h, err := r.Next()
+		assert.Nil(t, err)
+		assert.Equal(t, "file1", h.Name)
+
+		data, err := ioutil.ReadAll(r)
+		assert.Nil(t, err)
+		assert.Equal(t, "foo\n", string(data))
+
+		r.Close()
+	}
+}
+
+func TestWriterUnsupportedLevel(t *testing.T) {
+	for _, test := range []struct {
+		compress int
+		level    int
+	}{
+		{CompressNone, 1},
+		{CompressGzip, 10},
+		{CompressXZ, 10},
+		{CompressZstd, 23},
+		{CompressZstd, -1},
+	} {
+		_, err := NewWriterBufferLevel(test.compress, test.level)
+		assert.Equal(t, ErrUnsupportedLevel, err)
+	}
+}
+
 func TestWriterUnsupported(t *testing.T) {
 	_, err := NewWriterBuffer(CompressBzip2)
 	assert.Equal(t, ErrUnsupportedCompress, err)
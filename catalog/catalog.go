@@ -195,6 +195,12 @@ func (c *Catalog) Repositories() ([]*Repository, error) {
 
 // Recipe searches the catalog for a recipe.
 func (c *Catalog) Recipe(name string) (*recipe.Recipe, error) {
+	rcp, _, err := c.RecipeRepository(name)
+	return rcp, err
+}
+
+// RecipeRepository searches the catalog for a recipe, returning it along with the repository it belongs to.
+func (c *Catalog) RecipeRepository(name string) (*recipe.Recipe, *Repository, error) {
 	var (
 		q    query.Query
 		path string
@@ -214,11 +220,11 @@ func (c *Catalog) Recipe(name string) (*recipe.Recipe, error) {
 
 	result, err := c.index.Search(req)
 	if err != nil {
-		return nil, fmt.Errorf("cannot search index: %w", err)
+		return nil, nil, fmt.Errorf("cannot search index: %w", err)
 	}
 
 	if result.Total == 0 {
-		return nil, ErrRecipeNotFound
+		return nil, nil, ErrRecipeNotFound
 	}
 
 	// Search for default repository on duplicate recipes short names
@@ -236,7 +242,18 @@ func (c *Catalog) Recipe(name string) (*recipe.Recipe, error) {
 	}
 
 	dir, base := filepath.Dir(path), filepath.Base(path)
-	return recipe.LoadRecipe(filepath.Join(c.Path, "repositories", dir, string(base[0]), base))
+
+	repo, err := NewRepositoryFromPath(filepath.Join(c.Path, "repositories", dir))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rcp, err := repo.Recipe(base)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rcp, repo, nil
 }
 
 // UninstallRepository uninstalls a recipes repository from the catalog.
@@ -5,12 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
-	"text/template"
+	"sync"
+	"time"
 
 	humanize "github.com/dustin/go-humanize"
 	"github.com/h2non/filetype"
@@ -22,7 +24,9 @@ import (
 
 	"mkdeb.sh/catalog"
 	"mkdeb.sh/deb"
+	"mkdeb.sh/lint"
 	"mkdeb.sh/recipe"
+	"mkdeb.sh/upstream"
 
 	"mkdeb.sh/cmd/mkdeb/internal/handler"
 	"mkdeb.sh/cmd/mkdeb/internal/print"
@@ -35,6 +39,15 @@ var buildCommand = &cli.Command{
 	ArgsUsage: "RECIPE...",
 	Action:    execBuild,
 	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name: "compress",
+			Usage: "Package members compression as FORMAT[:LEVEL] or control=FORMAT[:LEVEL],data=FORMAT[:LEVEL] " +
+				"(formats: none, gzip, xz, zstd)",
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Report how upstream and recipe files would get installed without building package",
+		},
 		&cli.UintFlag{
 			Name:  "epoch, e",
 			Usage: "Package version epoch",
@@ -47,19 +60,40 @@ var buildCommand = &cli.Command{
 			Name:  "install, i",
 			Usage: "Install package after build",
 		},
+		&cli.IntFlag{
+			Name:  "jobs, j",
+			Usage: "Number of packages to build concurrently",
+			Value: 1,
+		},
+		&cli.BoolFlag{
+			Name:  "lint",
+			Usage: "Lint package contents after build",
+		},
 		&cli.StringFlag{
 			Name:  "recipe, R",
 			Usage: "Recipe base path",
 		},
+		&cli.BoolFlag{
+			Name:  "reproducible",
+			Usage: "Generate reproducible package (implied when SOURCE_DATE_EPOCH is set)",
+		},
 		&cli.IntFlag{
 			Name:  "revision, r",
 			Usage: "Package version revision",
 			Value: 1,
 		},
+		&cli.StringFlag{
+			Name:  "sign-key",
+			Usage: "OpenPGP private key path to sign package with (passphrase read from MKDEB_SIGN_PASSPHRASE)",
+		},
 		&cli.BoolFlag{
 			Name:  "skip-cache",
 			Usage: "Skip download cache",
 		},
+		&cli.BoolFlag{
+			Name:  "strict",
+			Usage: "Promote all package linting warnings to errors",
+		},
 		&cli.StringFlag{
 			Name:  "to, t",
 			Usage: "Package output path",
@@ -82,6 +116,14 @@ func execBuild(ctx *cli.Context) error {
 		}
 	}
 
+	if ctx.Bool("dry-run") && (install || ctx.Bool("lint")) {
+		return errors.New(`flag "--dry-run" cannot be used with "--install" or "--lint"`)
+	}
+
+	if ctx.Bool("strict") && !ctx.Bool("lint") {
+		return errors.New(`flag "--strict" requires "--lint"`)
+	}
+
 	if ctx.String("to") != "" && ctx.NArg() > 1 {
 		return errors.New(`flag "--to" cannot be used when multiple packages are being built`)
 	}
@@ -93,83 +135,147 @@ func execBuild(ctx *cli.Context) error {
 		}
 	}
 
+	opts := &buildOptions{}
+
+	err := opts.setReproducible(ctx.Bool("reproducible"))
+	if err != nil {
+		return err
+	}
+
+	compress := ctx.String("compress")
+	if compress != "" {
+		opts.controlCompression, opts.dataCompression, err = parseCompress(compress)
+		if err != nil {
+			return fmt.Errorf("invalid compression: %w", err)
+		}
+	}
+
+	signKey := ctx.String("sign-key")
+	if signKey != "" {
+		opts.signer, err = deb.NewOpenPGPSigner(signKey, []byte(os.Getenv("MKDEB_SIGN_PASSPHRASE")))
+		if err != nil {
+			return fmt.Errorf("cannot load signing key: %w", err)
+		}
+	}
+
 	c, err := catalog.New(catalogDir)
 	if err != nil {
 		return fmt.Errorf("cannot initialize catalog: %w", err)
 	}
 	defer c.Close()
 
-	for _, arg := range ctx.Args().Slice() {
-		var (
-			rcp *recipe.Recipe
-			err error
-		)
+	jobs := ctx.Int("jobs")
+	if jobs < 1 {
+		return errors.New(`flag "--jobs" must be greater than 0`)
+	}
 
-		name, arch, version := parseRef(arg)
-		if arch == "" {
-			arch = "all"
-		}
-		if version == "" {
-			return errors.New("missing recipe version")
-		}
+	opts.from = ctx.String("from")
+	opts.to = ctx.String("to")
+	opts.epoch = ctx.Uint("epoch")
+	opts.revision = ctx.Int("revision")
+	opts.skipCache = ctx.Bool("skip-cache")
+	opts.lint = ctx.Bool("lint")
+	opts.dryRun = ctx.Bool("dry-run")
 
-		rcpPath := ctx.String("recipe")
-		if rcpPath != "" {
-			rcp, err = recipe.LoadRecipe(rcpPath)
-		} else {
-			rcp, err = c.Recipe(name)
-		}
-		if err == catalog.ErrRecipeNotFound {
+	history, err := loadHistory()
+	if err != nil {
+		return fmt.Errorf("cannot load build history: %w", err)
+	}
+
+	// Resolve recipes prior to build, as catalog doesn't support concurrent access
+	var (
+		tasks []*buildTask
+		errs  buildErrors
+	)
+
+	for _, arg := range ctx.Args().Slice() {
+		argTasks, err := newBuildTasks(c, arg, ctx.String("recipe"))
+		if err == catalog.ErrRecipeNotFound && ctx.NArg() == 1 {
 			return err
 		} else if err != nil {
-			return fmt.Errorf("cannot load recipe: %w", err)
+			argTasks = []*buildTask{{ref: arg, err: err}}
 		}
 
-		print.Section("Package %s", ansi.Color(name, "green+b"))
+		tasks = append(tasks, argTasks...)
+	}
 
-		from := ctx.String("from")
-		if from == "" {
-			from, err = downloadArchive(arch, version, rcp, ctx.Bool("skip-cache"))
-			if err != nil {
-				return fmt.Errorf("cannot download upstream archive: %w", err)
+	if opts.to != "" && len(tasks) > 1 {
+		return errors.New(`flag "--to" cannot be used when multiple packages are being built`)
+	}
+
+	// Load linting configurations prior to build, applying the recipes repositories settings
+	if opts.lint {
+		configs := lintConfigs{}
+
+		for _, task := range tasks {
+			if task.err == nil {
+				task.lintConfig, task.err = configs.config(task.repo, ctx.Bool("strict"))
 			}
-		} else {
-			rcp.Source.URL = "<unused>"
 		}
+	}
 
-		fi, err := os.Stat(from)
-		if err == nil && fi.IsDir() {
-			print.Step("Using %q upstream folder...", from)
-		} else {
-			print.Step("Using %q upstream file...", from)
-		}
+	// Build packages using a pool of workers, buffering their output if running concurrently
+	queue := make(chan *buildTask)
+	wg := &sync.WaitGroup{}
 
-		// Get for output file path (will be overwritten if left empty)
-		epoch := ctx.Uint("epoch")
-		if epoch == 0 {
-			epoch = rcp.Control.Version.Epoch
-		}
+	for i := 0; i < jobs && i < len(tasks); i++ {
+		wg.Add(1)
 
-		to := ctx.String("to")
+		go func() {
+			defer wg.Done()
 
-		// Ensure recipe is valid before build
-		err = rcp.Validate()
-		if err != nil {
-			return err
+			for task := range queue {
+				out := print.NewPrinter(os.Stdout)
+				if jobs > 1 {
+					out = print.NewBufferedPrinter()
+				}
+
+				task.info, task.err = buildPackage(out, task, opts)
+
+				out.Flush(os.Stdout)
+			}
+		}()
+	}
+
+	for _, task := range tasks {
+		if task.err == nil {
+			queue <- task
 		}
+	}
+	close(queue)
 
-		info, err := createPackage(arch, version, epoch, ctx.Int("revision"), rcp, from, to)
-		if err != nil {
-			return fmt.Errorf("cannot create package: %w", err)
+	wg.Wait()
+
+	built := 0
+
+	for _, task := range tasks {
+		if task.err != nil {
+			errs = errs.append(task.ref, len(tasks), task.err)
+			continue
 		}
 
-		print.Summary("📦", info.String())
+		// Only record packages actually built
+		if !opts.dryRun {
+			history.record(task.repo, task.rcp, task.version)
+			built++
+		}
 
 		if install {
-			pkgs = append(pkgs, info)
+			pkgs = append(pkgs, task.info)
+		}
+	}
+
+	if built > 0 {
+		err = history.save()
+		if err != nil {
+			return fmt.Errorf("cannot save build history: %w", err)
 		}
 	}
 
+	if len(errs) > 0 {
+		return errs
+	}
+
 	if install && len(pkgs) > 0 {
 		print.Section("Install packages")
 
@@ -189,6 +295,246 @@ func execBuild(ctx *cli.Context) error {
 	return nil
 }
 
+type buildTask struct {
+	ref        string
+	name       string
+	arch       string
+	version    string
+	latest     bool
+	rcp        *recipe.Recipe
+	repo       *catalog.Repository
+	lintConfig *lint.Config
+	info       *packageInfo
+	err        error
+}
+
+// newBuildTasks creates build tasks given a package reference, expanding its architectures list if any.
+func newBuildTasks(c *catalog.Catalog, ref, rcpPath string) ([]*buildTask, error) {
+	var archs []string
+
+	name, arch, version := parseRef(ref)
+	if arch == "" {
+		arch = "all"
+	}
+
+	loadRecipe := func() (*recipe.Recipe, *catalog.Repository, error) {
+		var (
+			rcp  *recipe.Recipe
+			repo *catalog.Repository
+			err  error
+		)
+
+		if rcpPath != "" {
+			rcp, err = recipe.LoadRecipe(rcpPath)
+		} else {
+			rcp, repo, err = c.RecipeRepository(name)
+		}
+		if err == catalog.ErrRecipeNotFound {
+			return nil, nil, err
+		} else if err != nil {
+			return nil, nil, fmt.Errorf("cannot load recipe: %w", err)
+		}
+
+		return rcp, repo, nil
+	}
+
+	rcp, repo, err := loadRecipe()
+	if err != nil {
+		return nil, err
+	}
+
+	// Discover upstream latest version if none has been explicitly requested
+	latest := version == "" || version == "latest"
+	if latest {
+		if rcp.Source == nil || rcp.Source.Latest == nil {
+			return nil, errors.New("missing recipe version")
+		}
+
+		version, err = upstream.LatestVersion(rcp.Source.Latest)
+		if err != nil {
+			return nil, fmt.Errorf("cannot discover latest version: %w", err)
+		}
+	}
+
+	_, err = deb.ParseVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	if arch == "*" {
+		if rcp.Source == nil || len(rcp.Source.ArchMapping) == 0 {
+			return nil, errors.New("recipe declares no architecture")
+		}
+
+		for key := range rcp.Source.ArchMapping {
+			archs = append(archs, key)
+		}
+		sort.Strings(archs)
+	} else {
+		seen := map[string]bool{}
+
+		for _, arch := range strings.Split(arch, ",") {
+			if seen[arch] {
+				continue
+			}
+			seen[arch] = true
+
+			// Check for architectures support prior to build, as builds would otherwise fail one by one
+			if src := rcp.Arch(arch).Source; src == nil {
+				return nil, errors.New("recipe declares no architecture")
+			} else if _, ok := src.ArchMapping[arch]; !ok {
+				return nil, fmt.Errorf("unsupported architecture %q", arch)
+			}
+
+			archs = append(archs, arch)
+		}
+	}
+
+	tasks := []*buildTask{}
+
+	for idx, arch := range archs {
+		// Load a distinct recipe instance for each architecture, as build may alter it
+		if idx > 0 {
+			rcp, _, err = loadRecipe()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		tasks = append(tasks, &buildTask{
+			ref:     fmt.Sprintf("%s:%s=%s", name, arch, version),
+			name:    name,
+			arch:    arch,
+			version: version,
+			latest:  latest,
+			rcp:     rcp.Arch(arch),
+			repo:    repo,
+		})
+	}
+
+	return tasks, nil
+}
+
+func buildPackage(out *print.Printer, task *buildTask, opts *buildOptions) (*packageInfo, error) {
+	var err error
+
+	rcp := task.rcp
+
+	out.Section("Package %s", ansi.Color(task.name, "green+b"))
+
+	if task.latest {
+		out.Step("Using %q latest version...", task.version)
+	}
+
+	upstreamArch, ok := rcp.Source.ArchMapping[task.arch]
+	if !ok {
+		return nil, errors.New("unsupported architecture")
+	}
+
+	// Get for output file path (will be overwritten if left empty)
+	epoch := opts.epoch
+	if epoch == 0 && rcp.Control != nil {
+		epoch = rcp.Control.Version.Epoch
+	}
+
+	data := &recipe.TemplateData{
+		Name:         rcp.Name,
+		Version:      task.version,
+		Epoch:        epoch,
+		Arch:         upstreamArch,
+		DebArch:      task.arch,
+		UpstreamArch: upstreamArch,
+	}
+
+	from := opts.from
+	if from == "" {
+		from, err = downloadArchive(out, data, rcp, opts.skipCache)
+		if err != nil {
+			return nil, fmt.Errorf("cannot download upstream archive: %w", err)
+		}
+	} else {
+		rcp.Source.URL = "<unused>"
+	}
+
+	fi, err := os.Stat(from)
+	if err == nil && fi.IsDir() {
+		out.Step("Using %q upstream folder...", from)
+	} else {
+		out.Step("Using %q upstream file...", from)
+	}
+
+	// Ensure recipe is valid before build
+	err = rcp.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	err = rcp.RenderTemplates(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot render recipe: %w", err)
+	}
+
+	if opts.dryRun {
+		return nil, planPackage(out, rcp, from)
+	}
+
+	info, err := createPackage(out, task.arch, task.version, epoch, opts.revision, rcp, from, opts.to, opts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create package: %w", err)
+	}
+
+	if opts.lint {
+		out.Step("Linting package...")
+
+		// Package file has been closed once written, thus being complete
+		r, err := deb.Open(info.Path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open package: %w", err)
+		}
+
+		var ignore []string
+		if rcp.Lint != nil {
+			ignore = rcp.Lint.Ignore
+		}
+
+		problems, ok := lint.LintPackage(r, task.lintConfig, ignore)
+		out.Lint(rcp.Name, problems)
+
+		if !ok {
+			return nil, fmt.Errorf("package linting failed, keeping %q for inspection", info.Path)
+		}
+	}
+
+	out.Summary("📦", info.String())
+
+	return info, nil
+}
+
+// buildErrors is a list of packages build errors.
+type buildErrors []error
+
+func (e buildErrors) append(ref string, count int, err error) buildErrors {
+	// Only prefix errors with package reference if multiple packages are being built
+	if count > 1 {
+		err = fmt.Errorf("%s: %w", ref, err)
+	}
+
+	return append(e, err)
+}
+
+func (e buildErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	s := fmt.Sprintf("%d packages failed to build:", len(e))
+	for _, err := range e {
+		s += "\n  " + err.Error()
+	}
+
+	return s
+}
+
 func parseRef(input string) (string, string, string) {
 	var name, arch, version string
 
@@ -209,100 +555,254 @@ func parseRef(input string) (string, string, string) {
 	return name, arch, version
 }
 
-func downloadArchive(arch, version string, rcp *recipe.Recipe, force bool) (string, error) {
-	var path string
+// parseCompress parses compression settings, either applying to both control and data members or specified
+// separately for each of them.
+func parseCompress(input string) (*deb.Compression, *deb.Compression, error) {
+	var control, data *deb.Compression
 
-	v, ok := rcp.Source.ArchMapping[arch]
-	if !ok {
-		return "", errors.New("unsupported architecture")
+	if !strings.Contains(input, "=") {
+		c, err := deb.ParseCompression(input)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return &c, &c, nil
 	}
-	arch = v
 
-	// Generate URL from recipe template
-	buf := bytes.NewBuffer(nil)
+	for _, part := range strings.Split(input, ",") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, nil, fmt.Errorf("invalid %q setting", part)
+		}
+
+		c, err := deb.ParseCompression(kv[1])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch kv[0] {
+		case "control":
+			control = &c
+
+		case "data":
+			data = &c
+
+		default:
+			return nil, nil, fmt.Errorf("unknown %q member", kv[0])
+		}
+	}
+
+	return control, data, nil
+}
+
+var downloadLocks sync.Map
+
+func downloadArchive(out *print.Printer, data *recipe.TemplateData, rcp *recipe.Recipe, force bool) (string, error) {
+	var path string
 
-	tmpl, err := template.New("").Parse(rcp.Source.URL)
+	// Generate URL from recipe template
+	url, err := renderURL(rcp.Source.URL, data)
 	if err != nil {
-		return "", fmt.Errorf("cannot parse URL template: %w", err)
-	} else if err = tmpl.Execute(buf, struct{ Arch, Version string }{arch, version}); err != nil {
-		return "", fmt.Errorf("cannot execute URL template: %w", err)
+		return "", err
 	}
 
-	url := buf.String()
+	name := url
 	idx := strings.LastIndex(url, "/")
 	if idx != -1 {
-		path = filepath.Join(cacheDir, string(rcp.Name[0]), rcp.Name, url[idx+1:])
+		name = url[idx+1:]
+		path = filepath.Join(cacheDir, string(rcp.Name[0]), rcp.Name, name)
+	}
+
+	// Prevent concurrent builds from downloading the same file simultaneously
+	lock, _ := downloadLocks.LoadOrStore(path, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	sum, err := sourceChecksum(rcp.Source, data, name)
+	if err != nil {
+		return "", fmt.Errorf("cannot get upstream checksum: %w", err)
 	}
 
+	cached := false
 	if !force {
 		_, err := os.Stat(path)
 		if err == nil {
-			return path, nil
+			cached = true
+
+			if sum != nil {
+				err = sum.VerifyFile(path)
+				if errors.Is(err, recipe.ErrChecksumMismatch) {
+					out.Printf("cached file %s, downloading again\n", err)
+					cached = false
+				} else if err != nil {
+					return "", fmt.Errorf("cannot verify cached file: %w", err)
+				}
+			}
 		}
 	}
 
-	dirPath := filepath.Dir(path)
-	_, err = os.Stat(dirPath)
-	if os.IsNotExist(err) {
-		if err = os.MkdirAll(dirPath, 0755); err != nil {
-			return "", fmt.Errorf("cannot create cache directory: %w", err)
+	if !cached {
+		dirPath := filepath.Dir(path)
+		_, err = os.Stat(dirPath)
+		if os.IsNotExist(err) {
+			if err = os.MkdirAll(dirPath, 0755); err != nil {
+				return "", fmt.Errorf("cannot create cache directory: %w", err)
+			}
 		}
-	}
 
-	print.Step("Downloading %q...", url)
+		out.Step("Downloading %q...", url)
 
-	req, err := http.Get(url)
-	if err != nil {
-		return "", err
+		err = downloadFile(out, url, path)
+		if err != nil {
+			return "", err
+		}
+
+		if sum != nil {
+			err = sum.VerifyFile(path)
+			if err != nil {
+				// Never keep an unverified file in cache
+				os.Remove(path)
+				return "", fmt.Errorf("cannot verify %q: %w", url, err)
+			}
+
+			out.Printf("verified %s\n", sum)
+		}
 	}
-	defer req.Body.Close()
 
-	if req.StatusCode >= 400 {
-		return "", errors.New(req.Status)
+	if rcp.Source.SignatureURL != "" {
+		err = verifySignature(out, rcp, data, path, !cached)
+		if err != nil {
+			os.Remove(path)
+			return "", fmt.Errorf("cannot verify upstream signature: %w", err)
+		}
 	}
 
+	return path, nil
+}
+
+func downloadFile(out *print.Printer, url, path string) error {
+	body, length, err := upstream.Get(url)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
 	f, err := os.Create(path)
 	if err != nil {
-		return "", err
+		return err
 	}
 	defer f.Close()
 
-	contentLength := uint64(req.ContentLength)
+	contentLength := uint64(length)
 	printLength := 0
 	progressFn := func(s uint64) {
 		var str string
 
-		if req.ContentLength == -1 || s == contentLength {
+		if length == -1 || s == contentLength {
 			str = fmt.Sprintf("\rdownload %s", humanize.Bytes(s))
 		} else {
 			str = fmt.Sprintf("\rdownload %s/%s", humanize.Bytes(s), humanize.Bytes(contentLength))
 		}
 
-		fmt.Print(str)
+		out.Printf("%s", str)
 		diff := printLength - len(str)
 		if diff > 0 {
-			fmt.Print(strings.Repeat(" ", diff))
+			out.Printf("%s", strings.Repeat(" ", diff))
 		}
 		printLength = len(str)
 	}
 
-	_, err = io.Copy(f, progress.New(req.Body, progressFn))
+	// Only report final size when output is buffered
+	var r io.Reader = body
+	if !out.Buffered() {
+		r = progress.New(body, progressFn)
+	}
+
+	n, err := io.Copy(f, r)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	fmt.Print("\n")
+	if out.Buffered() {
+		out.Printf("download %s", humanize.Bytes(uint64(n)))
+	}
 
-	return path, nil
+	out.Printf("\n")
+
+	return nil
 }
 
-func createPackage(arch, version string, epoch uint, revision int, rcp *recipe.Recipe, from,
-	to string) (*packageInfo, error) {
+func renderURL(text string, data *recipe.TemplateData) (string, error) {
+	url, err := recipe.Render(text, data)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
 
-	var (
-		f       handler.Func
-		subtype string
-	)
+	return url, nil
+}
+
+func sourceChecksum(src *recipe.Source, data *recipe.TemplateData, name string) (*recipe.Checksum, error) {
+	sum, err := src.Checksum(data.Version, data.DebArch)
+	if err != nil || sum != nil || src.ChecksumURL == "" {
+		return sum, err
+	}
+
+	// Fetch checksums listing from upstream
+	url, err := renderURL(src.ChecksumURL, data)
+	if err != nil {
+		return nil, err
+	}
+
+	listing, err := upstream.Fetch(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return recipe.ParseChecksumFile(bytes.NewReader(listing), name)
+}
+
+func verifySignature(out *print.Printer, rcp *recipe.Recipe, data *recipe.TemplateData, path string,
+	refresh bool) error {
+
+	url, err := renderURL(rcp.Source.SignatureURL, data)
+	if err != nil {
+		return err
+	}
+
+	keyring, err := upstream.LoadKeyring(rcp.KeyFiles)
+	if err != nil {
+		return fmt.Errorf("cannot load trusted keys: %w", err)
+	}
+
+	// Retrieve detached signature, caching it next to the upstream archive
+	sigPath := filepath.Join(filepath.Dir(path), url[strings.LastIndex(url, "/")+1:])
+
+	sig, err := ioutil.ReadFile(sigPath)
+	if refresh || err != nil {
+		sig, err = upstream.Fetch(url)
+		if err != nil {
+			return err
+		}
+
+		err = ioutil.WriteFile(sigPath, sig, 0644)
+		if err != nil {
+			return fmt.Errorf("cannot write signature file: %w", err)
+		}
+	}
+
+	err = keyring.Verify(path, sig)
+	if err != nil {
+		os.Remove(sigPath)
+		return err
+	}
+
+	out.Printf("verified signature %q\n", url)
+
+	return nil
+}
+
+func createPackage(out *print.Printer, arch, version string, epoch uint, revision int, rcp *recipe.Recipe, from,
+	to string, opts *buildOptions) (*packageInfo, error) {
 
 	_, ok := rcp.Source.ArchMapping[arch]
 	if !ok {
@@ -313,6 +813,20 @@ func createPackage(arch, version string, epoch uint, revision int, rcp *recipe.R
 	if err != nil {
 		return nil, err
 	}
+	defer p.Close()
+
+	if opts.reproducible {
+		p.SetReproducible(opts.modTime)
+	}
+
+	p.Signer = opts.signer
+
+	if opts.controlCompression != nil {
+		p.ControlCompression = *opts.controlCompression
+	}
+	if opts.dataCompression != nil {
+		p.DataCompression = *opts.dataCompression
+	}
 
 	desc := rcp.Description
 	if rcp.Control.Description != "" {
@@ -341,71 +855,70 @@ func createPackage(arch, version string, epoch uint, revision int, rcp *recipe.R
 	if len(rcp.Control.Conflicts) > 0 {
 		p.Control.Conflicts = rcp.Control.Conflicts
 	}
+	if len(rcp.Control.Provides) > 0 {
+		p.Control.Provides = rcp.Control.Provides
+	}
+	if len(rcp.Control.Replaces) > 0 {
+		p.Control.Replaces = rcp.Control.Replaces
+	}
+	if len(rcp.Control.BuiltUsing) > 0 {
+		p.Control.BuiltUsing = rcp.Control.BuiltUsing
+	}
+
+	p.Control.Source = rcp.Control.Source
+	p.Control.MultiArch = rcp.Control.MultiArch
+	p.Control.Essential = rcp.Control.Essential
+	p.Control.Protected = rcp.Control.Protected
+	p.Control.Custom = rcp.Control.Fields
 
 	if len(rcp.Maintainer) > 0 {
 		p.Control.Maintainer = rcp.Maintainer
 	}
 
 	if len(rcp.ControlFiles) > 0 {
-		print.Step("Adding control files...")
+		out.Step("Adding control files...")
 
 		for _, f := range rcp.ControlFiles {
 			name := f.FileInfo.Name()
 
-			fmt.Printf("append %q (%s)\n", name, humanize.Bytes(uint64(f.FileInfo.Size())))
+			out.Printf("append %q (%s)\n", name, humanize.Bytes(uint64(f.FileInfo.Size())))
 
 			src, err := os.Open(f.Path)
 			if err != nil {
 				return nil, fmt.Errorf("cannot open %q file: %w", name, err)
 			}
 
-			if err = p.AddControlFile(name, src, f.FileInfo); err != nil {
+			err = p.AddControlFile(name, src, f.FileInfo)
+			src.Close()
+			if err != nil {
 				return nil, fmt.Errorf("cannot add %q file: %w", name, err)
 			}
 		}
 	}
 
-	print.Step("Adding upstream files...")
+	out.Step("Adding upstream files...")
 
-	switch rcp.Source.Type {
-	case "archive":
-		typ, err := filetype.MatchFile(from)
-		if err != nil {
-			return nil, err
-		}
-
-		switch typ.MIME.Subtype {
-		case "gzip", "x-bzip2", "x-tar", "x-xz":
-			f = handler.Tar
-
-		case "zip":
-			f = handler.Zip
-		}
-
-		subtype = typ.MIME.Subtype
-
-	case "file":
-		f = handler.File
-	}
-
-	if f == nil {
-		return nil, errors.New("unsupported source")
+	f, subtype, err := sourceHandler(rcp, from)
+	if err != nil {
+		return nil, err
 	}
 
-	err = f(p, rcp, from, subtype)
+	err = f(from, subtype, rcp.Source.Strip, func(e *handler.Entry) error {
+		return addUpstreamEntry(out, p, rcp, e)
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	if len(rcp.RecipeFiles) > 0 {
-		print.Step("Adding recipe files...")
+		out.Step("Adding recipe files...")
 
 		for _, f := range rcp.RecipeFiles {
 			name := f.FileInfo.Name()
 
 			path, confFile, ok := rcp.InstallPath(name, rcp.Install.Recipe)
 			if ok {
-				fmt.Printf("append %q as %q (%s)\n", name, path, humanize.Bytes(uint64(f.FileInfo.Size())))
+				out.Printf("append %q as %q (%s)\n", name, path, humanize.Bytes(uint64(f.FileInfo.Size())))
 
 				if confFile {
 					p.RegisterConfFile(path)
@@ -416,7 +929,9 @@ func createPackage(arch, version string, epoch uint, revision int, rcp *recipe.R
 					return nil, fmt.Errorf("cannot open %q file: %w", name, err)
 				}
 
-				if err = p.AddFile(path, src, f.FileInfo); err != nil {
+				err = p.AddFile(path, src, f.FileInfo)
+				src.Close()
+				if err != nil {
 					return nil, fmt.Errorf("cannot add %q file: %w", name, err)
 				}
 			}
@@ -424,10 +939,10 @@ func createPackage(arch, version string, epoch uint, revision int, rcp *recipe.R
 	}
 
 	if len(rcp.Dirs) > 0 {
-		print.Step("Adding recipe directories...")
+		out.Step("Adding recipe directories...")
 
 		for _, path := range rcp.Dirs {
-			fmt.Printf("append %q\n", path)
+			out.Printf("append %q\n", path)
 
 			if err = p.AddDir(path, 0755); err != nil {
 				return nil, fmt.Errorf("cannot add %q directory: %w", path, err)
@@ -436,10 +951,10 @@ func createPackage(arch, version string, epoch uint, revision int, rcp *recipe.R
 	}
 
 	if len(rcp.Links) > 0 {
-		print.Step("Adding recipe symbolic links...")
+		out.Step("Adding recipe symbolic links...")
 
 		for dst, src := range rcp.Links {
-			fmt.Printf("link %q to %q\n", src, dst)
+			out.Printf("link %q to %q\n", src, dst)
 
 			if err = p.AddLink(dst, src); err != nil {
 				return nil, fmt.Errorf("cannot add %q link: %w", dst, err)
@@ -470,9 +985,15 @@ func createPackage(arch, version string, epoch uint, revision int, rcp *recipe.R
 	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 
 	err = p.Write(file)
+	if err == nil {
+		err = file.Close()
+	}
 	if err != nil {
+		// Don't leave a truncated package behind
+		os.Remove(info.Path)
 		return nil, fmt.Errorf("cannot write package: %w", err)
 	}
 
@@ -486,6 +1007,68 @@ func createPackage(arch, version string, epoch uint, revision int, rcp *recipe.R
 	return info, nil
 }
 
+// sourceHandler returns the handler to use for walking an upstream source, along with the source MIME subtype.
+func sourceHandler(rcp *recipe.Recipe, from string) (handler.Func, string, error) {
+	switch rcp.Source.Type {
+	case "archive":
+		typ, err := filetype.MatchFile(from)
+		if err != nil {
+			return nil, "", err
+		}
+
+		switch typ.MIME.Subtype {
+		case "gzip", "x-bzip2", "x-tar", "x-xz":
+			return handler.Tar, typ.MIME.Subtype, nil
+
+		case "zip":
+			return handler.Zip, typ.MIME.Subtype, nil
+		}
+
+	case "file":
+		return handler.File, "", nil
+	}
+
+	return nil, "", errors.New("unsupported source")
+}
+
+// addUpstreamEntry adds an upstream source entry to the package if it matches the recipe installation rules.
+func addUpstreamEntry(out *print.Printer, p *deb.Package, rcp *recipe.Recipe, e *handler.Entry) error {
+	path, confFile, ok := rcp.InstallPath(e.Name, rcp.Install.Upstream)
+	if !ok {
+		return nil
+	}
+
+	out.Printf("append %q as %q (%s)\n", e.Name, path, humanize.Bytes(uint64(e.FileInfo.Size())))
+
+	if confFile {
+		p.RegisterConfFile(path)
+	}
+
+	mode := e.FileInfo.Mode()
+
+	switch {
+	case mode&os.ModeDir == os.ModeDir:
+		err := p.AddDir(path, mode)
+		if err != nil {
+			return fmt.Errorf("cannot add %q dir: %w", e.Name, err)
+		}
+
+	case mode&os.ModeSymlink == os.ModeSymlink:
+		err := p.AddLink(path, e.LinkName)
+		if err != nil {
+			return fmt.Errorf("cannot add %q link: %w", e.Name, err)
+		}
+
+	default:
+		err := p.AddFile(path, e.Reader, e.FileInfo)
+		if err != nil {
+			return fmt.Errorf("cannot add %q file: %w", e.Name, err)
+		}
+	}
+
+	return nil
+}
+
 func installPackages(pkgs []*packageInfo) error {
 	var paths []string
 
@@ -506,6 +1089,42 @@ func installPackages(pkgs []*packageInfo) error {
 	return cmd.Run()
 }
 
+type buildOptions struct {
+	from               string
+	to                 string
+	epoch              uint
+	revision           int
+	skipCache          bool
+	lint               bool
+	dryRun             bool
+	reproducible       bool
+	modTime            time.Time
+	signer             deb.Signer
+	controlCompression *deb.Compression
+	dataCompression    *deb.Compression
+}
+
+// setReproducible enables the reproducible mode if requested or if SOURCE_DATE_EPOCH is set, the latter defining
+// packages timestamps.
+func (o *buildOptions) setReproducible(force bool) error {
+	modTime, ok, err := deb.SourceDateEpoch()
+	if err != nil {
+		return err
+	} else if !ok && !force {
+		return nil
+	}
+
+	// Fallback to Unix epoch if no SOURCE_DATE_EPOCH value has been provided
+	if !ok {
+		modTime = time.Unix(0, 0).UTC()
+	}
+
+	o.reproducible = true
+	o.modTime = modTime
+
+	return nil
+}
+
 type packageInfo struct {
 	Path string
 	Size int64
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"mkdeb.sh/deb"
+	"mkdeb.sh/lint"
+	"mkdeb.sh/recipe"
+
+	"mkdeb.sh/cmd/mkdeb/internal/handler"
+	"mkdeb.sh/cmd/mkdeb/internal/print"
+)
+
+func TestAddUpstreamEntrySpecialFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mkdeb-build-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "foo.tar")
+	writeTestTar(t, path, &tar.Header{Name: "foo/foo.fifo", Typeflag: tar.TypeFifo, Mode: 0644})
+
+	rcp := &recipe.Recipe{
+		Install: &recipe.Install{
+			Upstream: recipe.InstallMap{"/var/lib/foo": {{Pattern: "*.fifo"}}},
+		},
+	}
+
+	p, err := deb.NewPackage("foo", "all", "1.2.3", 0, 1)
+	assert.Nil(t, err)
+	defer p.Close()
+
+	err = handler.Tar(path, "x-tar", 1, func(e *handler.Entry) error {
+		return addUpstreamEntry(print.NewPrinter(ioutil.Discard), p, rcp, e)
+	})
+	assert.Nil(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, p.Write(buf))
+
+	r, err := deb.NewReader(buf)
+	assert.Nil(t, err)
+
+	var names []string
+	for _, h := range r.Files {
+		names = append(names, h.Name)
+	}
+	assert.Contains(t, names, "./var/lib/foo/foo.fifo")
+}
+
+func TestNewBuildTasksArchs(t *testing.T) {
+	tasks, err := newBuildTasks(nil, "foo:amd64,arm64,amd64=1.2.3", "testdata/build")
+	assert.Nil(t, err)
+
+	var refs []string
+	for _, task := range tasks {
+		refs = append(refs, task.ref)
+	}
+	assert.Equal(t, []string{"foo:amd64=1.2.3", "foo:arm64=1.2.3"}, refs)
+
+	_, err = newBuildTasks(nil, "foo:amd64,i386=1.2.3", "testdata/build")
+	assert.Equal(t, `unsupported architecture "i386"`, err.Error())
+}
+
+func TestBuildPackageReproducible(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mkdeb-build-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	if v, ok := os.LookupEnv("SOURCE_DATE_EPOCH"); ok {
+		defer os.Setenv("SOURCE_DATE_EPOCH", v)
+	} else {
+		defer os.Unsetenv("SOURCE_DATE_EPOCH")
+	}
+	os.Unsetenv("SOURCE_DATE_EPOCH")
+
+	defer func() { deb.Now = time.Now }()
+
+	buildTime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	opts := &buildOptions{revision: 1}
+	assert.Nil(t, opts.setReproducible(false))
+	assert.False(t, opts.reproducible)
+
+	// Packages built at different times from upstream files having different times differ, unless reproducible
+	first := testBuildPackage(t, dir, opts, buildTime)
+	second := testBuildPackage(t, dir, opts, buildTime.Add(time.Hour))
+	assert.NotEqual(t, first, second)
+
+	os.Setenv("SOURCE_DATE_EPOCH", "1577934245")
+	assert.Nil(t, opts.setReproducible(false))
+	assert.True(t, opts.reproducible)
+	assert.Equal(t, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), opts.modTime)
+
+	first = testBuildPackage(t, dir, opts, buildTime)
+	second = testBuildPackage(t, dir, opts, buildTime.Add(time.Hour))
+	assert.Equal(t, first, second)
+}
+
+func TestBuildPackageLint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mkdeb-build-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	opts := &buildOptions{revision: 1, lint: true}
+	opts.from = filepath.Join(dir, "foo-1.2.3.tar")
+	opts.to = filepath.Join(dir, "foo.deb")
+
+	writeTestTar(t, opts.from, &tar.Header{Name: "foo-1.2.3/foo", Typeflag: tar.TypeReg, Mode: 0755})
+
+	for _, test := range []struct {
+		config *lint.Config
+		err    string
+	}{
+		{config: &lint.Config{}},
+		{
+			config: &lint.Config{Strict: true},
+			err:    `package linting failed, keeping "` + opts.to + `" for inspection`,
+		},
+		{config: &lint.Config{Strict: true, Disable: []string{"package-copyright-missing"}}},
+	} {
+		tasks, err := newBuildTasks(nil, "foo:amd64=1.2.3", "testdata/build")
+		assert.Nil(t, err)
+		tasks[0].lintConfig = test.config
+
+		out := print.NewBufferedPrinter()
+
+		_, err = buildPackage(out, tasks[0], opts)
+		if test.err != "" {
+			assert.Equal(t, test.err, err.Error())
+			assert.FileExists(t, opts.to)
+		} else {
+			assert.Nil(t, err)
+		}
+	}
+}
+
+// testBuildPackage builds the test recipe package as if at a given time, upstream files being modified at that time
+// too, returning the package checksum.
+func testBuildPackage(t *testing.T, dir string, opts *buildOptions, buildTime time.Time) [sha256.Size]byte {
+	deb.Now = func() time.Time { return buildTime }
+
+	opts.from = filepath.Join(dir, "foo-1.2.3.tar")
+	opts.to = filepath.Join(dir, "foo.deb")
+
+	writeTestTar(t, opts.from,
+		&tar.Header{Name: "foo-1.2.3/", Typeflag: tar.TypeDir, Mode: 0755, ModTime: buildTime},
+		&tar.Header{Name: "foo-1.2.3/foo", Typeflag: tar.TypeReg, Mode: 0755, ModTime: buildTime},
+	)
+
+	tasks, err := newBuildTasks(nil, "foo:amd64=1.2.3", "testdata/build")
+	assert.Nil(t, err)
+
+	_, err = buildPackage(print.NewPrinter(ioutil.Discard), tasks[0], opts)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile(opts.to)
+	assert.Nil(t, err)
+
+	return sha256.Sum256(data)
+}
+
+// writeTestTar writes a tar archive holding the given entries, regular files having their name as content.
+func writeTestTar(t *testing.T, path string, headers ...*tar.Header) {
+	f, err := os.Create(path)
+	assert.Nil(t, err)
+
+	w := tar.NewWriter(f)
+	for _, h := range headers {
+		if h.Typeflag == tar.TypeReg {
+			h.Size = int64(len(h.Name))
+		}
+
+		assert.Nil(t, w.WriteHeader(h))
+
+		if h.Typeflag == tar.TypeReg {
+			_, err = w.Write([]byte(h.Name))
+			assert.Nil(t, err)
+		}
+	}
+
+	assert.Nil(t, w.Close())
+	assert.Nil(t, f.Close())
+}
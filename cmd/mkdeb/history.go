@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"mkdeb.sh/catalog"
+	"mkdeb.sh/recipe"
+)
+
+// buildHistory is the record of the last upstream version built for each recipe, keyed by recipe reference
+// regardless of the one used to build it.
+type buildHistory map[string]*buildRecord
+
+type buildRecord struct {
+	Version string    `json:"version"`
+	Time    time.Time `json:"time"`
+}
+
+func historyPath() string {
+	return filepath.Join(dataDir, "history.json")
+}
+
+func loadHistory() (buildHistory, error) {
+	h := buildHistory{}
+
+	data, err := ioutil.ReadFile(historyPath())
+	if os.IsNotExist(err) {
+		return h, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &h)
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+func (h buildHistory) record(repo *catalog.Repository, rcp *recipe.Recipe, version string) {
+	h[historyKey(repo, rcp)] = &buildRecord{
+		Version: version,
+		Time:    time.Now().UTC(),
+	}
+}
+
+func (h buildHistory) lookup(repo *catalog.Repository, rcp *recipe.Recipe) (*buildRecord, bool) {
+	record, ok := h[historyKey(repo, rcp)]
+	return record, ok
+}
+
+// historyKey returns the history key of a recipe, qualified with its repository name as indexed in the catalog.
+// Recipes not loaded from the catalog are keyed by their name.
+func historyKey(repo *catalog.Repository, rcp *recipe.Recipe) string {
+	if repo == nil {
+		return rcp.Name
+	}
+
+	return repo.Name + "/" + rcp.Name
+}
+
+func (h buildHistory) save() error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(dataDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	// Write history to a temporary file first to prevent leaving a truncated file behind
+	tmp, err := ioutil.TempFile(dataDir, "history-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(append(data, '\n'))
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), historyPath())
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mkdeb.sh/catalog"
+	"mkdeb.sh/recipe"
+)
+
+func TestHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mkdeb-history-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	defer func(v string) { dataDir = v }(dataDir)
+	dataDir = dir
+
+	core := &catalog.Repository{Name: catalog.DefaultRepository}
+	other := &catalog.Repository{Name: "owner/repo"}
+	rcp := &recipe.Recipe{Name: "foo"}
+
+	h, err := loadHistory()
+	assert.Nil(t, err)
+
+	// Recipes sharing a name in distinct repositories are recorded separately
+	h.record(core, rcp, "1.2.3")
+	h.record(other, rcp, "2.0.0")
+	h.record(nil, rcp, "3.0.0")
+	assert.Nil(t, h.save())
+
+	h, err = loadHistory()
+	assert.Nil(t, err)
+
+	for _, test := range []struct {
+		repo    *catalog.Repository
+		version string
+	}{
+		{core, "1.2.3"},
+		{other, "2.0.0"},
+		{nil, "3.0.0"},
+	} {
+		record, ok := h.lookup(test.repo, rcp)
+		assert.True(t, ok)
+		assert.Equal(t, test.version, record.Version)
+	}
+
+	_, ok := h.lookup(&catalog.Repository{Name: "owner/unknown"}, rcp)
+	assert.False(t, ok)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/urfave/cli/v2"
+
+	"mkdeb.sh/deb"
+)
+
+const inspectFormat = `{{ .Control }}
+Members:
+{{ range .Members }}   {{ .Name }}	{{ .Size }}
+{{ end }}{{ if .ConfFiles }}
+Configuration files:
+{{ range .ConfFiles }}   {{ . }}
+{{ end }}{{ end }}
+Files:
+{{ range .Files }}   {{ .Mode }}	{{ .User }}/{{ .Group }}	{{ .Size }}	{{ .ModTime.UTC.Format "2006-01-02 15:04" }}	` +
+	`{{ .Name }}{{ if .LinkName }} -> {{ .LinkName }}{{ end }}
+{{ end }}`
+
+var inspectCommand = &cli.Command{
+	Name:      "inspect",
+	Usage:     "Inspect Debian package",
+	ArgsUsage: "FILE.deb",
+	Action:    execInspect,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "Output template format",
+		},
+	},
+}
+
+func execInspect(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		cli.ShowCommandHelpAndExit(ctx, "inspect", 1)
+	}
+
+	r, err := deb.Open(ctx.Args().First())
+	if err != nil {
+		return fmt.Errorf("cannot open package: %w", err)
+	}
+
+	format := ctx.String("format")
+	if format == "" {
+		format = inspectFormat
+	} else {
+		format = strings.TrimSpace(format) + "\n"
+	}
+
+	tmpl, err := template.New("").Parse(format)
+	if err != nil {
+		return fmt.Errorf("invalid format: %w", err)
+	}
+
+	tr := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	err = tmpl.Execute(tr, r)
+	if err != nil {
+		return fmt.Errorf("cannot execute template: %w", err)
+	}
+	tr.Flush()
+
+	return nil
+}
@@ -1,14 +1,28 @@
 package handler
 
 import (
+	"io"
+	"os"
 	"strings"
-
-	"mkdeb.sh/deb"
-	"mkdeb.sh/recipe"
 )
 
-// Func is an upstream source handler function.
-type Func func(*deb.Package, *recipe.Recipe, string, string) error
+// Entry is an upstream source entry.
+type Entry struct {
+	// Name is the entry path relative to the source root, having its leading components stripped if requested.
+	Name     string
+	FileInfo os.FileInfo
+	LinkName string
+
+	// Reader is the entry content reader, set for every entry but directories and symbolic links.
+	Reader io.Reader
+}
+
+// WalkFunc is the function called for each upstream source entry.
+type WalkFunc func(e *Entry) error
+
+// Func is an upstream source handler function, walking the source entries given its path, its MIME subtype and
+// the number of leading path components to strip from entries names.
+type Func func(path, typ string, strip int, f WalkFunc) error
 
 func stripName(name string, n int) string {
 	if n == 0 {
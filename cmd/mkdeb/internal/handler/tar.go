@@ -5,14 +5,11 @@ import (
 	"io"
 	"os"
 
-	humanize "github.com/dustin/go-humanize"
 	"mkdeb.sh/archive"
-	"mkdeb.sh/deb"
-	"mkdeb.sh/recipe"
 )
 
 // Tar is an upstream source tar handler.
-func Tar(p *deb.Package, recipe *recipe.Recipe, path, typ string) error {
+func Tar(path, typ string, strip int, f WalkFunc) error {
 	var compress int
 
 	switch typ {
@@ -30,13 +27,13 @@ func Tar(p *deb.Package, recipe *recipe.Recipe, path, typ string) error {
 	}
 
 	// Create a new reader for the source archive
-	f, err := os.Open(path)
+	file, err := os.Open(path)
 	if err != nil {
 		return fmt.Errorf("cannot open upstream archive: %w", err)
 	}
-	defer f.Close()
+	defer file.Close()
 
-	src, err := archive.NewReader(f, compress)
+	src, err := archive.NewReader(file, compress)
 	if err != nil {
 		return fmt.Errorf("cannot initialize archive reader: %w", err)
 	}
@@ -50,38 +47,20 @@ func Tar(p *deb.Package, recipe *recipe.Recipe, path, typ string) error {
 			return err
 		}
 
-		name := h.Name
-		if recipe.Source.Strip > 0 {
-			name = stripName(name, recipe.Source.Strip)
+		e := &Entry{
+			Name:     stripName(h.Name, strip),
+			FileInfo: h.FileInfo(),
+			LinkName: h.LinkName,
 		}
 
-		path, confFile, ok := recipe.InstallPath(name, recipe.Install.Upstream)
-		if ok {
-			fmt.Printf("append %q as %q (%s)\n", name, path, humanize.Bytes(uint64(h.Size)))
-
-			if confFile {
-				p.RegisterConfFile(path)
-			}
-
-			switch {
-			case h.Mode&os.ModeDir == os.ModeDir:
-				err = p.AddDir(path, h.Mode)
-				if err != nil {
-					return fmt.Errorf("cannot add %q dir: %w", name, err)
-				}
-
-			case h.Mode&os.ModeSymlink == os.ModeSymlink:
-				err = p.AddLink(path, h.LinkName)
-				if err != nil {
-					return fmt.Errorf("cannot add %q link: %w", name, err)
-				}
+		// Special files such as FIFOs or devices get their (empty) content read as regular files would
+		if h.Mode&(os.ModeDir|os.ModeSymlink) == 0 {
+			e.Reader = src
+		}
 
-			default:
-				err = p.AddFile(path, src, h.FileInfo())
-				if err != nil {
-					return fmt.Errorf("cannot add %q file: %w", name, err)
-				}
-			}
+		err = f(e)
+		if err != nil {
+			return err
 		}
 	}
 
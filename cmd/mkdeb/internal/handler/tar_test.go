@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"archive/tar"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTar(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mkdeb-handler-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "foo.tar")
+
+	f, err := os.Create(path)
+	assert.Nil(t, err)
+
+	w := tar.NewWriter(f)
+	for _, h := range []*tar.Header{
+		{Name: "foo/", Typeflag: tar.TypeDir, Mode: 0755},
+		{Name: "foo/bin/foo", Typeflag: tar.TypeReg, Mode: 0755, Size: 3},
+		{Name: "foo/bin/bar", Typeflag: tar.TypeSymlink, Linkname: "foo", Mode: 0777},
+		{Name: "foo/run/foo.fifo", Typeflag: tar.TypeFifo, Mode: 0644},
+	} {
+		assert.Nil(t, w.WriteHeader(h))
+		if h.Size > 0 {
+			_, err = w.Write([]byte("foo"))
+			assert.Nil(t, err)
+		}
+	}
+	assert.Nil(t, w.Close())
+	assert.Nil(t, f.Close())
+
+	entries := map[string]string{}
+
+	err = Tar(path, "x-tar", 1, func(e *Entry) error {
+		if e.Reader == nil {
+			entries[e.Name] = "<nil> " + e.LinkName
+			return nil
+		}
+
+		data, err := ioutil.ReadAll(e.Reader)
+		entries[e.Name] = string(data)
+
+		return err
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, map[string]string{
+		"":             "<nil> ",
+		"bin/foo":      "foo",
+		"bin/bar":      "<nil> foo",
+		"run/foo.fifo": "",
+	}, entries)
+}
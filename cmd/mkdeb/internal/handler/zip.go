@@ -3,15 +3,13 @@ package handler
 import (
 	"archive/zip"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
-
-	humanize "github.com/dustin/go-humanize"
-	"mkdeb.sh/deb"
-	"mkdeb.sh/recipe"
 )
 
 // Zip is an upstream source zip handler.
-func Zip(p *deb.Package, recipe *recipe.Recipe, path, typ string) error {
+func Zip(path, typ string, strip int, f WalkFunc) error {
 	// Create a new reader for the source archive
 	r, err := zip.OpenReader(path)
 	if err != nil {
@@ -20,39 +18,74 @@ func Zip(p *deb.Package, recipe *recipe.Recipe, path, typ string) error {
 	defer r.Close()
 
 	for _, file := range r.File {
-		name := file.Name
-		if recipe.Source.Strip > 0 {
-			name = stripName(name, recipe.Source.Strip)
+		err = zipEntry(file, strip, f)
+		if err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
+
+func zipEntry(file *zip.File, strip int, f WalkFunc) error {
+	e := &Entry{
+		Name:     stripName(file.Name, strip),
+		FileInfo: file.FileInfo(),
+	}
+
+	switch {
+	case file.Mode().IsDir():
+		// Directories have no content
+
+	case file.Mode()&os.ModeSymlink == os.ModeSymlink:
+		// Symbolic links targets are stored as their content, thus read whether or not the link gets installed
+		src, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("cannot open %q file: %w", file.Name, err)
+		}
+		defer src.Close()
+
+		target, err := ioutil.ReadAll(src)
+		if err != nil {
+			return fmt.Errorf("cannot read %q link: %w", file.Name, err)
+		}
+
+		e.LinkName = string(target)
 
-		path, confFile, ok := recipe.InstallPath(name, recipe.Install.Upstream)
-		if ok {
-			fmt.Printf("append %q as %q (%s)\n", name, path, humanize.Bytes(file.UncompressedSize64))
-
-			if confFile {
-				p.RegisterConfFile(path)
-			}
-
-			f, err := file.Open()
-			if err != nil {
-				return fmt.Errorf("cannot open %q file: %w", file.Name, err)
-			}
-			defer f.Close()
-
-			mode := file.Mode()
-			if mode&os.ModeDir == os.ModeDir {
-				err = p.AddDir(path, mode)
-				if err != nil {
-					return fmt.Errorf("cannot add %q dir: %w", name, err)
-				}
-			} else {
-				err = p.AddFile(path, f, file.FileInfo())
-				if err != nil {
-					return fmt.Errorf("cannot add %q file: %w", name, err)
-				}
-			}
+	default:
+		src := &zipReader{file: file}
+		defer src.Close()
+
+		e.Reader = src
+	}
+
+	return f(e)
+}
+
+// zipReader is a zip file content reader, only opening the file on first read so that entries not being installed
+// don't get decompressed.
+type zipReader struct {
+	file *zip.File
+	rc   io.ReadCloser
+}
+
+func (r *zipReader) Read(b []byte) (int, error) {
+	if r.rc == nil {
+		rc, err := r.file.Open()
+		if err != nil {
+			return 0, fmt.Errorf("cannot open %q file: %w", r.file.Name, err)
 		}
+
+		r.rc = rc
 	}
 
-	return nil
+	return r.rc.Read(b)
+}
+
+func (r *zipReader) Close() error {
+	if r.rc == nil {
+		return nil
+	}
+
+	return r.rc.Close()
 }
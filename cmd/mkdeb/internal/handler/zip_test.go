@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// zipUnsupportedMethod is a compression method unknown to zip readers, failing entries reading.
+const zipUnsupportedMethod = 99
+
+func TestZip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mkdeb-handler-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "foo.zip")
+
+	f, err := os.Create(path)
+	assert.Nil(t, err)
+
+	w := zip.NewWriter(f)
+	w.RegisterCompressor(zipUnsupportedMethod, func(w io.Writer) (io.WriteCloser, error) {
+		return nopWriteCloser{w}, nil
+	})
+
+	for _, file := range []struct {
+		name    string
+		mode    os.FileMode
+		method  uint16
+		content string
+	}{
+		{"foo/", os.ModeDir | 0755, zip.Store, ""},
+		{"foo/bin/foo", 0755, zip.Deflate, "foo"},
+		{"foo/bin/bar", os.ModeSymlink | 0777, zip.Store, "foo"},
+		{"foo/share/unsupported", 0644, zipUnsupportedMethod, "unsupported"},
+	} {
+		h := &zip.FileHeader{Name: file.name, Method: file.method}
+		h.SetMode(file.mode)
+
+		fw, err := w.CreateHeader(h)
+		assert.Nil(t, err)
+
+		_, err = fw.Write([]byte(file.content))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, w.Close())
+	assert.Nil(t, f.Close())
+
+	entries := map[string]string{}
+
+	// Entries not being read must not get opened, as unsupported ones would fail
+	err = Zip(path, "zip", 1, func(e *Entry) error {
+		if e.Reader == nil {
+			entries[e.Name] = "<nil> " + e.LinkName
+			return nil
+		} else if e.Name == "share/unsupported" {
+			entries[e.Name] = "<skipped>"
+			return nil
+		}
+
+		data, err := ioutil.ReadAll(e.Reader)
+		entries[e.Name] = string(data)
+
+		return err
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, map[string]string{
+		"":                  "<nil> ",
+		"bin/foo":           "foo",
+		"bin/bar":           "<nil> foo",
+		"share/unsupported": "<skipped>",
+	}, entries)
+
+	// Reading unsupported entries fails
+	err = Zip(path, "zip", 1, func(e *Entry) error {
+		if e.Reader != nil {
+			_, err := ioutil.ReadAll(e.Reader)
+			return err
+		}
+
+		return nil
+	})
+	assert.Equal(t, zip.ErrAlgorithm, errors.Unwrap(err))
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -7,7 +7,6 @@ import (
 
 	"github.com/mgutz/ansi"
 	"mkdeb.sh/lint"
-	"mkdeb.sh/recipe"
 )
 
 var enableEmoji = true
@@ -24,40 +23,8 @@ func Error(s string, args ...interface{}) {
 }
 
 // Lint prints linting problems.
-func Lint(rcp *recipe.Recipe, problems []*lint.Problem) {
-	var level string
-
-	if problems == nil {
-		return
-	}
-
-	for _, p := range problems {
-		if p.Level == lint.LevelError {
-			level = ansi.Color("E:", "red")
-		} else {
-			level = ansi.Color("W:", "yellow")
-		}
-
-		args := []string{""}
-		for _, arg := range p.Args {
-			format := "%v"
-
-			switch arg.(type) {
-			case string, fmt.Stringer:
-				format = "%q"
-			}
-
-			args = append(args, fmt.Sprintf(format, arg))
-		}
-
-		fmt.Printf(
-			"%s %s: %s%s\n",
-			level,
-			rcp.Name,
-			p.Tag,
-			strings.Join(args, " "),
-		)
-	}
+func Lint(name string, problems []*lint.Problem) {
+	stdout.Lint(name, problems)
 }
 
 // LintInfo prints linting rule information.
@@ -77,24 +44,15 @@ func LintInfo(info *lint.RuleInfo) {
 
 // Section prints a section message.
 func Section(s string, args ...interface{}) {
-	print("green", s, args...)
+	stdout.Section(s, args...)
 }
 
 // Step prints a step message.
 func Step(s string, args ...interface{}) {
-	print("blue", s, args...)
+	stdout.Step(s, args...)
 }
 
 // Summary prints a summary message.
 func Summary(emoji, s string, args ...interface{}) {
-	Step("Summary")
-	if enableEmoji {
-		fmt.Print(emoji + "  ")
-	}
-	fmt.Printf(s+"\n", args...)
-}
-
-func print(color, s string, args ...interface{}) {
-	fmt.Print(ansi.Color("==> ", color))
-	fmt.Printf(ansi.Color(s, "default+b")+"\n", args...)
+	stdout.Summary(emoji, s, args...)
 }
@@ -0,0 +1,129 @@
+package print
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/mgutz/ansi"
+	"mkdeb.sh/lint"
+)
+
+var (
+	stdout = NewPrinter(os.Stdout)
+
+	// flushLock serializes buffered printers flushes.
+	flushLock sync.Mutex
+)
+
+// Printer is a commands output printer.
+type Printer struct {
+	w      io.Writer
+	buffer *bytes.Buffer
+}
+
+// NewPrinter creates a new printer instance writing to a given io.Writer.
+func NewPrinter(w io.Writer) *Printer {
+	return &Printer{w: w}
+}
+
+// NewBufferedPrinter creates a new printer instance keeping its output aside until flushed.
+func NewBufferedPrinter() *Printer {
+	buf := bytes.NewBuffer(nil)
+	return &Printer{w: buf, buffer: buf}
+}
+
+// Buffered returns whether or not the printer output is buffered.
+func (p *Printer) Buffered() bool {
+	return p.buffer != nil
+}
+
+// Flush writes the buffered output of the printer to a given io.Writer, ensuring concurrent flushes don't get
+// interleaved.
+func (p *Printer) Flush(w io.Writer) error {
+	if p.buffer == nil {
+		return nil
+	}
+
+	flushLock.Lock()
+	defer flushLock.Unlock()
+
+	_, err := p.buffer.WriteTo(w)
+	return err
+}
+
+// Lint prints linting problems.
+func (p *Printer) Lint(name string, problems []*lint.Problem) {
+	var level string
+
+	for _, problem := range problems {
+		if problem.Level == lint.LevelError {
+			level = ansi.Color("E:", "red")
+		} else {
+			level = ansi.Color("W:", "yellow")
+		}
+
+		// Prefix with problem position if known, allowing editors to jump to it
+		pos := ""
+		if problem.Position != nil {
+			pos = problem.Position.String() + ": "
+		}
+
+		fmt.Fprintf(p.w, "%s%s %s: %s\n", pos, level, name, problem)
+	}
+}
+
+// LintFixes prints applied linting fixes.
+func (p *Printer) LintFixes(name string, fixes []*lint.Fix) {
+	for _, fix := range fixes {
+		pos := ""
+		if fix.Position != nil {
+			pos = fix.Position.String() + ": "
+		}
+
+		// Only detail single-line changes, as multi-line ones would get unreadable
+		change := ""
+		if !strings.Contains(fix.Old+fix.New, "\n") {
+			change = fmt.Sprintf(" %q → %q", fix.Old, fix.New)
+		}
+
+		fmt.Fprintf(p.w, "%s%s %s: fixed %s%s\n", pos, ansi.Color("F:", "green"), name, fix.Tag, change)
+	}
+}
+
+// Printf prints a formatted message.
+func (p *Printer) Printf(s string, args ...interface{}) {
+	fmt.Fprintf(p.w, s, args...)
+}
+
+// Write satisfies the io.Writer interface.
+func (p *Printer) Write(b []byte) (int, error) {
+	return p.w.Write(b)
+}
+
+// Section prints a section message.
+func (p *Printer) Section(s string, args ...interface{}) {
+	p.print("green", s, args...)
+}
+
+// Step prints a step message.
+func (p *Printer) Step(s string, args ...interface{}) {
+	p.print("blue", s, args...)
+}
+
+// Summary prints a summary message.
+func (p *Printer) Summary(emoji, s string, args ...interface{}) {
+	p.Step("Summary")
+	if enableEmoji {
+		fmt.Fprint(p.w, emoji+"  ")
+	}
+	fmt.Fprintf(p.w, s+"\n", args...)
+}
+
+func (p *Printer) print(color, s string, args ...interface{}) {
+	fmt.Fprint(p.w, ansi.Color("==> ", color))
+	fmt.Fprintf(p.w, ansi.Color(s, "default+b")+"\n", args...)
+}
@@ -1,24 +1,47 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 
 	"github.com/urfave/cli/v2"
 
 	"mkdeb.sh/catalog"
+	"mkdeb.sh/deb"
 	"mkdeb.sh/lint"
 	"mkdeb.sh/recipe"
 
 	"mkdeb.sh/cmd/mkdeb/internal/print"
 )
 
+const lintFormatText = "text"
+
 var lintCommand = &cli.Command{
 	Name:      "lint",
-	Usage:     "Run linter on recipes",
-	ArgsUsage: "(--tags TAGS...|[RECIPE...])",
+	Usage:     "Run linter on recipes or packages",
+	ArgsUsage: "(--tags TAGS...|--deb FILE...|[--fix] [RECIPE...])",
 	Action:    execLint,
 	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "deb",
+			Usage: "Lint Debian package files instead of recipes",
+		},
+		&cli.BoolFlag{
+			Name:  "fix",
+			Usage: "Fix recipes problems having mechanical fixes",
+		},
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "Output format (text, json, sarif or checkstyle)",
+			Value: lintFormatText,
+		},
+		&cli.BoolFlag{
+			Name:  "strict",
+			Usage: "Promote all warnings to errors",
+		},
 		&cli.BoolFlag{
 			Name:  "tags",
 			Usage: "Display linting rule tags information",
@@ -27,7 +50,12 @@ var lintCommand = &cli.Command{
 }
 
 func execLint(ctx *cli.Context) error {
-	var failed bool
+	var (
+		reports []*lint.Report
+		failed  bool
+	)
+
+	configs := lintConfigs{}
 
 	if ctx.Bool("tags") {
 		for _, arg := range ctx.Args().Slice() {
@@ -37,41 +65,120 @@ func execLint(ctx *cli.Context) error {
 		return nil
 	}
 
-	c, err := catalog.New(catalogDir)
-	if err != nil {
-		return fmt.Errorf("cannot initialize catalog: %w", err)
+	format := ctx.String("format")
+	switch format {
+	case lintFormatText, lint.FormatCheckstyle, lint.FormatJSON, lint.FormatSARIF:
+	default:
+		return fmt.Errorf("%w: %q", lint.ErrUnsupportedFormat, format)
+	}
+
+	if ctx.Bool("fix") && ctx.Bool("deb") {
+		return errors.New(`flags "--fix" and "--deb" cannot be used together`)
+	}
+
+	// Report fixes on the error output when using structured formats, as they would get mixed with results
+	fixOut := print.NewPrinter(os.Stdout)
+	if format != lintFormatText {
+		fixOut = print.NewPrinter(os.Stderr)
+	}
+
+	report := func(name string, repo *catalog.Repository, problems []*lint.Problem, ok bool) {
+		if !ok {
+			failed = true
+		}
+
+		if format == lintFormatText {
+			print.Lint(name, problems)
+			return
+		}
+
+		report := &lint.Report{Recipe: name, Problems: problems}
+		if repo != nil {
+			report.Repository = repo.Name
+
+			// Report files relative to the repository root, matching the paths found in pull requests
+			for _, p := range problems {
+				if p.Position != nil {
+					if rel, err := filepath.Rel(repo.Path, p.Position.File); err == nil {
+						p.Position.File = filepath.ToSlash(rel)
+					}
+				}
+			}
+		}
+
+		reports = append(reports, report)
 	}
-	defer c.Close()
 
-	if ctx.NArg() > 0 {
+	if ctx.Bool("deb") {
+		if ctx.NArg() == 0 {
+			return errors.New(`flag "--deb" requires at least one package file`)
+		}
+
 		for _, arg := range ctx.Args().Slice() {
-			rcp, err := c.Recipe(arg)
+			r, err := deb.Open(arg)
 			if err != nil {
-				return err
+				return fmt.Errorf("cannot open %q package: %w", arg, err)
 			}
 
-			problems, ok := lint.Lint(rcp)
-			if !ok {
-				failed = true
-			}
-			print.Lint(rcp, problems)
+			problems, ok := lint.LintPackage(r, &lint.Config{Strict: ctx.Bool("strict")}, nil)
+			report(r.Control.Name, nil, problems, ok)
 		}
 	} else {
-		err = c.Walk(func(rcp *recipe.Recipe, repo *catalog.Repository, err error) error {
+		c, err := catalog.New(catalogDir)
+		if err != nil {
+			return fmt.Errorf("cannot initialize catalog: %w", err)
+		}
+		defer c.Close()
+
+		run := func(rcp *recipe.Recipe, repo *catalog.Repository) error {
+			cfg, err := configs.config(repo, ctx.Bool("strict"))
 			if err != nil {
 				return err
 			}
 
-			problems, ok := lint.Lint(rcp)
-			if !ok {
-				failed = true
+			if ctx.Bool("fix") {
+				rcp, err = fixRecipe(fixOut, rcp)
+				if err != nil {
+					return fmt.Errorf("cannot fix %q recipe: %w", rcp.Name, err)
+				}
 			}
-			print.Lint(rcp, problems)
+
+			problems, ok := lint.Lint(rcp, cfg)
+			report(rcp.Name, repo, problems, ok)
 
 			return nil
-		})
+		}
+
+		if ctx.NArg() > 0 {
+			for _, arg := range ctx.Args().Slice() {
+				rcp, repo, err := c.RecipeRepository(arg)
+				if err != nil {
+					return err
+				}
+
+				err = run(rcp, repo)
+				if err != nil {
+					return err
+				}
+			}
+		} else {
+			err = c.Walk(func(rcp *recipe.Recipe, repo *catalog.Repository, err error) error {
+				if err != nil {
+					return err
+				}
+
+				return run(rcp, repo)
+			})
+			if err != nil {
+				return fmt.Errorf("cannot walk recipes: %w", err)
+			}
+		}
+	}
+
+	if format != lintFormatText {
+		err := lint.WriteReports(os.Stdout, format, reports)
 		if err != nil {
-			return fmt.Errorf("cannot walk recipes: %w", err)
+			return fmt.Errorf("cannot write reports: %w", err)
 		}
 	}
 
@@ -81,3 +188,72 @@ func execLint(ctx *cli.Context) error {
 
 	return nil
 }
+
+// fixRecipe applies mechanical fixes to a recipe file, returning the recipe reloaded from its fixed file.
+func fixRecipe(out *print.Printer, rcp *recipe.Recipe) (*recipe.Recipe, error) {
+	path := filepath.Join(rcp.Path, "recipe.yaml")
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return rcp, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return rcp, err
+	}
+
+	fixed, fixes, err := lint.ApplyFixes(data)
+	if err != nil {
+		return rcp, err
+	} else if len(fixes) == 0 {
+		return rcp, nil
+	}
+
+	err = ioutil.WriteFile(path, fixed, fi.Mode())
+	if err != nil {
+		return rcp, err
+	}
+
+	for _, fix := range fixes {
+		fix.Position.File = path
+	}
+	out.LintFixes(rcp.Name, fixes)
+
+	fixedRcp, err := recipe.LoadRecipe(rcp.Path)
+	if err != nil {
+		return rcp, err
+	}
+
+	return fixedRcp, nil
+}
+
+// lintConfigs are the loaded recipes repositories linting configurations, by repository path.
+type lintConfigs map[string]*lint.Config
+
+// config returns the linting configuration applying to recipes of a repository, loading it if needed. An empty
+// configuration is returned if repo is nil.
+func (c lintConfigs) config(repo *catalog.Repository, strict bool) (*lint.Config, error) {
+	cfg := &lint.Config{}
+
+	if repo != nil {
+		if c[repo.Path] == nil {
+			repoCfg, err := lint.LoadConfig(repo.Path)
+			if err != nil {
+				return nil, fmt.Errorf("cannot load %q linting configuration: %w", repo.Name, err)
+			}
+
+			c[repo.Path] = repoCfg
+		}
+
+		// Copy repository configuration, as it might get altered by command flags
+		repoCfg := *c[repo.Path]
+		cfg = &repoCfg
+	}
+
+	if strict {
+		cfg.Strict = true
+	}
+
+	return cfg, nil
+}
@@ -28,7 +28,11 @@ func main() {
 			buildCommand,
 			cleanupCommand,
 			helpCommand,
+			inspectCommand,
 			lintCommand,
+			migrateCommand,
+			outdatedCommand,
+			publishCommand,
 			repoCommand,
 			searchCommand,
 			updateCommand,
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"mkdeb.sh/recipe"
+
+	"mkdeb.sh/cmd/mkdeb/internal/print"
+)
+
+var migrateCommand = &cli.Command{
+	Name:      "migrate",
+	Usage:     "Migrate recipes to the current format version",
+	ArgsUsage: "PATH...",
+	Action:    execMigrate,
+}
+
+func execMigrate(ctx *cli.Context) error {
+	var count int
+
+	if ctx.NArg() == 0 {
+		cli.ShowCommandHelpAndExit(ctx, "migrate", 1)
+	}
+
+	print.Section("Migrate recipes")
+
+	for _, path := range ctx.Args().Slice() {
+		path = filepath.Join(path, "recipe.yaml")
+
+		fi, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		migrated, err := recipe.Migrate(data)
+		if err != nil {
+			return fmt.Errorf("cannot migrate %q: %w", path, err)
+		}
+
+		if bytes.Equal(data, migrated) {
+			fmt.Printf("skip %q, already up-to-date\n", path)
+			continue
+		}
+
+		err = ioutil.WriteFile(path, migrated, fi.Mode())
+		if err != nil {
+			return fmt.Errorf("cannot write %q: %w", path, err)
+		}
+
+		fmt.Printf("migrate %q to version %d\n", path, recipe.CurrentVersion)
+
+		count++
+	}
+
+	message.Set(language.English, "migrate.count", plural.Selectf(1, "%d",
+		plural.One, "Operation migrated %d recipe",
+		plural.Other, "Operation migrated %d recipes",
+	))
+
+	print.Summary("📝", message.NewPrinter(language.English).Sprintf("migrate.count", count))
+
+	return nil
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"facette.io/natsort"
+	"github.com/urfave/cli/v2"
+
+	"mkdeb.sh/catalog"
+	"mkdeb.sh/deb"
+	"mkdeb.sh/recipe"
+	"mkdeb.sh/upstream"
+)
+
+const outdatedFormat = "{{ .Name }}\t{{ or .Current \"-\" }}\t{{ or .Latest \"-\" }}\t{{ .Status }}\n"
+
+// latestVersion discovers the latest upstream version of a recipe source.
+var latestVersion = upstream.LatestVersion
+
+var outdatedCommand = &cli.Command{
+	Name:      "outdated",
+	Usage:     "Check for newer upstream versions",
+	ArgsUsage: "[RECIPE...]",
+	Action:    execOutdated,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "all",
+			Usage: "Include recipes never built and up-to-date ones",
+		},
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "Output template format",
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "Output results as JSON",
+		},
+	},
+}
+
+type outdatedEntry struct {
+	Name     string `json:"name"`
+	Current  string `json:"current,omitempty"`
+	Latest   string `json:"latest,omitempty"`
+	Outdated bool   `json:"outdated"`
+	Error    string `json:"error,omitempty"`
+
+	repo *catalog.Repository
+	rcp  *recipe.Recipe
+}
+
+func (e *outdatedEntry) Status() string {
+	switch {
+	case e.Error != "":
+		return "error"
+
+	case e.Current == "":
+		return "not built"
+
+	case e.Outdated:
+		return "outdated"
+	}
+
+	return "up-to-date"
+}
+
+func execOutdated(ctx *cli.Context) error {
+	var entries []*outdatedEntry
+
+	if ctx.String("format") != "" && ctx.Bool("json") {
+		return errors.New(`flags "--format" and "--json" cannot be used together`)
+	}
+
+	if !catalog.Ready(catalogDir) {
+		err := ctx.App.Run([]string{ctx.App.Name, "repo", "add", catalog.DefaultRepository})
+		if err != nil {
+			return err
+		}
+	}
+
+	c, err := catalog.New(catalogDir)
+	if err != nil {
+		return fmt.Errorf("cannot initialize catalog: %w", err)
+	}
+	defer c.Close()
+
+	history, err := loadHistory()
+	if err != nil {
+		return fmt.Errorf("cannot load build history: %w", err)
+	}
+
+	all := ctx.Bool("all")
+
+	if ctx.NArg() > 0 {
+		for _, name := range ctx.Args().Slice() {
+			rcp, repo, err := c.RecipeRepository(name)
+			if err == catalog.ErrRecipeNotFound {
+				return fmt.Errorf("%s: %w", name, err)
+			} else if err != nil {
+				return fmt.Errorf("cannot load %q recipe: %w", name, err)
+			}
+
+			entries = append(entries, &outdatedEntry{Name: name, repo: repo, rcp: rcp})
+		}
+	} else {
+		// Only check recipes which have already been built, unless requested otherwise
+		err = c.Walk(func(rcp *recipe.Recipe, repo *catalog.Repository, err error) error {
+			if err != nil || rcp.Source == nil || rcp.Source.Latest == nil {
+				return nil
+			} else if _, ok := history.lookup(repo, rcp); !ok && !all {
+				return nil
+			}
+
+			name := rcp.Name
+			if repo.Name != catalog.DefaultRepository {
+				name = repo.Name + "/" + name
+			}
+
+			entries = append(entries, &outdatedEntry{Name: name, repo: repo, rcp: rcp})
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("cannot walk catalog: %w", err)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return natsort.Compare(entries[i].Name, entries[j].Name)
+	})
+
+	failed := 0
+	for _, entry := range entries {
+		checkOutdated(entry, history)
+		if entry.Error != "" {
+			failed++
+		}
+	}
+
+	// Filter out up-to-date and never built recipes if not requested
+	if !all && ctx.NArg() == 0 {
+		result := []*outdatedEntry{}
+		for _, entry := range entries {
+			if entry.Outdated || entry.Error != "" {
+				result = append(result, entry)
+			}
+		}
+		entries = result
+	}
+
+	err = writeOutdated(os.Stdout, entries, ctx.String("format"), ctx.Bool("json"))
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		s := fmt.Sprintf("%d recipes failed to check:", failed)
+		if failed == 1 {
+			s = "1 recipe failed to check:"
+		}
+
+		for _, entry := range entries {
+			if entry.Error != "" {
+				s += fmt.Sprintf("\n  %s: %s", entry.Name, entry.Error)
+			}
+		}
+
+		return errors.New(s)
+	}
+
+	return nil
+}
+
+// writeOutdated writes outdated check results to w, either as JSON or using a template format (a table being
+// written if format is empty).
+func writeOutdated(w io.Writer, entries []*outdatedEntry, format string, asJSON bool) error {
+	if asJSON {
+		if entries == nil {
+			entries = []*outdatedEntry{}
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		err := enc.Encode(entries)
+		if err != nil {
+			return fmt.Errorf("cannot encode results: %w", err)
+		}
+
+		return nil
+	} else if len(entries) == 0 {
+		fmt.Fprintln(w, "All recipes are up-to-date")
+		return nil
+	}
+
+	header := format == ""
+	if header {
+		format = outdatedFormat
+	} else {
+		format = strings.TrimSpace(format) + "\n"
+	}
+
+	tmpl, err := template.New("").Parse(format)
+	if err != nil {
+		return fmt.Errorf("invalid format: %w", err)
+	}
+
+	tr := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if header {
+		fmt.Fprintln(tr, "NAME\tCURRENT\tLATEST\tSTATUS")
+	}
+	for _, entry := range entries {
+		err = tmpl.Execute(tr, entry)
+		if err != nil {
+			return fmt.Errorf("cannot execute template: %w", err)
+		}
+	}
+
+	return tr.Flush()
+}
+
+// checkOutdated discovers the latest upstream version of a recipe and compares it against the last one built.
+func checkOutdated(entry *outdatedEntry, history buildHistory) {
+	if record, ok := history.lookup(entry.repo, entry.rcp); ok {
+		entry.Current = record.Version
+	}
+
+	if entry.rcp.Source == nil || entry.rcp.Source.Latest == nil {
+		entry.Error = "recipe declares no latest version discovery"
+		return
+	}
+
+	latest, err := latestVersion(entry.rcp.Source.Latest)
+	if err != nil {
+		entry.Error = err.Error()
+		return
+	}
+	entry.Latest = latest
+
+	if entry.Current == "" {
+		return
+	}
+
+	current, err := deb.ParseVersion(entry.Current)
+	if err != nil {
+		entry.Error = fmt.Sprintf("invalid recorded version: %s", err)
+		return
+	}
+
+	// Latest version has already been validated upon discovery
+	v, _ := deb.ParseVersion(latest)
+
+	entry.Outdated = deb.Compare(v, current) > 0
+}
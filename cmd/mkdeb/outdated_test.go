@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mkdeb.sh/catalog"
+	"mkdeb.sh/recipe"
+	"mkdeb.sh/upstream"
+)
+
+func TestOutdated(t *testing.T) {
+	defer func() { latestVersion = upstream.LatestVersion }()
+
+	latestVersion = func(latest *recipe.Latest) (string, error) {
+		if latest.Repository == "" {
+			return "", errors.New("discovery failed")
+		}
+
+		return map[string]string{"foo/foo": "1.2.4", "foo/bar": "2.0.0", "foo/baz": "1.0.0"}[latest.Repository], nil
+	}
+
+	repo := &catalog.Repository{Name: catalog.DefaultRepository}
+	history := buildHistory{
+		"mkdeb/core/foo": {Version: "1.2.3"},
+		"mkdeb/core/bar": {Version: "2.0.0"},
+		"owner/repo/foo": {Version: "0.1.0"},
+	}
+
+	newEntry := func(name, latestRepo string) *outdatedEntry {
+		rcp := &recipe.Recipe{Name: name, Source: &recipe.Source{Latest: &recipe.Latest{Repository: latestRepo}}}
+		return &outdatedEntry{Name: name, repo: repo, rcp: rcp}
+	}
+
+	entries := []*outdatedEntry{
+		newEntry("foo", "foo/foo"),
+		newEntry("bar", "foo/bar"),
+		newEntry("baz", "foo/baz"),
+		newEntry("qux", ""),
+	}
+
+	for _, entry := range entries {
+		checkOutdated(entry, history)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, writeOutdated(buf, entries, "", false))
+	assert.Equal(t, `NAME   CURRENT   LATEST   STATUS
+foo    1.2.3     1.2.4    outdated
+bar    2.0.0     2.0.0    up-to-date
+baz    -         1.0.0    not built
+qux    -         -        error
+`, buf.String())
+
+	buf.Reset()
+	assert.Nil(t, writeOutdated(buf, entries, "{{ .Name }}={{ .Status }}", false))
+	assert.Equal(t, "foo=outdated\nbar=up-to-date\nbaz=not built\nqux=error\n", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, writeOutdated(buf, entries[:1], "", true))
+	assert.Equal(t, `[
+  {
+    "name": "foo",
+    "current": "1.2.3",
+    "latest": "1.2.4",
+    "outdated": true
+  }
+]
+`, buf.String())
+
+	buf.Reset()
+	assert.Nil(t, writeOutdated(buf, nil, "", true))
+	assert.Equal(t, "[]\n", buf.String())
+
+	assert.Equal(t, "discovery failed", entries[3].Error)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"mkdeb.sh/recipe"
+
+	"mkdeb.sh/cmd/mkdeb/internal/handler"
+	"mkdeb.sh/cmd/mkdeb/internal/print"
+)
+
+// installPlan is a package installation plan, reporting how upstream and recipe files match installation rules.
+type installPlan struct {
+	rcp       *recipe.Recipe
+	installed int
+	unmatched []string
+	used      map[string]bool
+}
+
+// planPackage walks the upstream source and the recipe files, reporting how they would get installed without
+// building the package.
+func planPackage(out *print.Printer, rcp *recipe.Recipe, from string) error {
+	f, subtype, err := sourceHandler(rcp, from)
+	if err != nil {
+		return err
+	}
+
+	plan := &installPlan{rcp: rcp, used: map[string]bool{}}
+
+	out.Step("Planning upstream files...")
+
+	tw := plan.table(out)
+	err = f(from, subtype, rcp.Source.Strip, func(e *handler.Entry) error {
+		plan.add(tw, "upstream", e.Name, e.FileInfo, rcp.Install.Upstream)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	tw.Flush()
+
+	if len(rcp.RecipeFiles) > 0 {
+		out.Step("Planning recipe files...")
+
+		tw = plan.table(out)
+		for _, f := range rcp.RecipeFiles {
+			plan.add(tw, "recipe", f.FileInfo.Name(), f.FileInfo, rcp.Install.Recipe)
+		}
+		tw.Flush()
+	}
+
+	if len(plan.unmatched) > 0 {
+		out.Step("Unmatched files")
+
+		for _, name := range plan.unmatched {
+			out.Printf("%s\n", name)
+		}
+	}
+
+	unused := append(plan.unused("upstream", rcp.Install.Upstream), plan.unused("recipe", rcp.Install.Recipe)...)
+	if len(unused) > 0 {
+		out.Step("Unused install rules")
+
+		for _, rule := range unused {
+			out.Printf("%s\n", rule)
+		}
+	}
+
+	out.Summary("📋", "%s: %d installed, %d unmatched, %d unused rules", rcp.Name, plan.installed,
+		len(plan.unmatched), len(unused))
+
+	return nil
+}
+
+func (p *installPlan) table(out *print.Printer) *tabwriter.Writer {
+	tw := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "SOURCE\tDESTINATION\tRULE\tCONFFILE")
+
+	return tw
+}
+
+// add reports how a source file matches installation rules, directories only being reported if matched.
+func (p *installPlan) add(tw *tabwriter.Writer, kind, name string, fi os.FileInfo, m recipe.InstallMap) {
+	match, ok := p.rcp.MatchInstall(name, m)
+	if !ok {
+		if !fi.IsDir() {
+			fmt.Fprintf(tw, "%s\t-\t-\t-\n", name)
+			p.unmatched = append(p.unmatched, fmt.Sprintf("%s %q", kind, name))
+		}
+
+		return
+	}
+
+	conffile := "no"
+	if match.Rule.ConfFile {
+		conffile = "yes"
+	}
+
+	rule := ruleName(kind, match.Base, match.Index)
+	fmt.Fprintf(tw, "%s\t%s\t%s %q\t%s\n", name, match.Path, rule, match.Rule.Pattern, conffile)
+
+	p.installed++
+	p.used[rule] = true
+}
+
+// unused returns the installation rules no file has been matched by.
+func (p *installPlan) unused(kind string, m recipe.InstallMap) []string {
+	var result []string
+
+	bases := make([]string, 0, len(m))
+	for base := range m {
+		bases = append(bases, base)
+	}
+	sort.Strings(bases)
+
+	for _, base := range bases {
+		for idx, rule := range m[base] {
+			if name := ruleName(kind, base, idx); !p.used[name] {
+				result = append(result, fmt.Sprintf("%s %q", name, rule.Pattern))
+			}
+		}
+	}
+
+	return result
+}
+
+func ruleName(kind, base string, idx int) string {
+	return fmt.Sprintf("%s %s[%d]", kind, base, idx)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"mkdeb.sh/apt"
+	"mkdeb.sh/deb"
+
+	"mkdeb.sh/cmd/mkdeb/internal/print"
+)
+
+var publishCommand = &cli.Command{
+	Name:      "publish",
+	Usage:     "Publish packages to a local APT repository",
+	ArgsUsage: "FILE.deb...",
+	Action:    execPublish,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "component",
+			Usage: "Repository component name (pool layout only)",
+			Value: "main",
+		},
+		&cli.StringFlag{
+			Name:  "label",
+			Usage: "Repository label",
+		},
+		&cli.StringFlag{
+			Name:  "layout",
+			Usage: "Repository layout (flat or pool)",
+			Value: apt.LayoutFlat,
+		},
+		&cli.StringFlag{
+			Name:  "origin",
+			Usage: "Repository origin",
+		},
+		&cli.StringFlag{
+			Name:     "path",
+			Usage:    "Repository directory path",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "sign-key",
+			Usage: "OpenPGP private key path to sign repository with (passphrase read from MKDEB_SIGN_PASSPHRASE)",
+		},
+		&cli.StringFlag{
+			Name:  "suite",
+			Usage: "Repository suite name (pool layout only)",
+			Value: "stable",
+		},
+	},
+}
+
+func execPublish(ctx *cli.Context) error {
+	if ctx.NArg() == 0 {
+		cli.ShowCommandHelpAndExit(ctx, "publish", 1)
+	}
+
+	repo, err := apt.NewRepository(ctx.String("path"), ctx.String("layout"))
+	if err != nil {
+		return err
+	}
+
+	repo.Suite = ctx.String("suite")
+	repo.Component = ctx.String("component")
+	repo.Origin = ctx.String("origin")
+	repo.Label = ctx.String("label")
+
+	modTime, ok, err := deb.SourceDateEpoch()
+	if err != nil {
+		return err
+	} else if ok {
+		repo.Date = modTime
+	}
+
+	signKey := ctx.String("sign-key")
+	if signKey != "" {
+		repo.Signer, err = deb.NewOpenPGPSigner(signKey, []byte(os.Getenv("MKDEB_SIGN_PASSPHRASE")))
+		if err != nil {
+			return fmt.Errorf("cannot load signing key: %w", err)
+		}
+	}
+
+	print.Section("Repository %s", repo.Path)
+
+	print.Step("Adding packages...")
+
+	for _, path := range ctx.Args().Slice() {
+		rel, err := repo.Add(path)
+		if err != nil {
+			return fmt.Errorf("cannot add %q: %w", path, err)
+		}
+
+		fmt.Printf("add %q as %q\n", path, rel)
+	}
+
+	print.Step("Updating indexes...")
+
+	files, err := repo.Update()
+	if err != nil {
+		return fmt.Errorf("cannot update repository: %w", err)
+	}
+
+	for _, file := range files {
+		fmt.Printf("write %q\n", file)
+	}
+
+	message.Set(language.English, "publish.count", plural.Selectf(1, "%d",
+		plural.One, "Published %d package to %s",
+		plural.Other, "Published %d packages to %s",
+	))
+
+	print.Summary("🗄", "%s", message.NewPrinter(language.English).Sprintf("publish.count", ctx.NArg(), repo.Path))
+
+	return nil
+}
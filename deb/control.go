@@ -1,7 +1,10 @@
 package deb
 
 import (
+	"bytes"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
 	wordwrap "github.com/mitchellh/go-wordwrap"
@@ -10,13 +13,25 @@ import (
 // ControlDescriptionWrap is the control description text wrapping value.
 const ControlDescriptionWrap = 76
 
+// Multi-Arch field values:
+const (
+	MultiArchAllowed = "allowed"
+	MultiArchForeign = "foreign"
+	MultiArchNo      = "no"
+	MultiArchSame    = "same"
+)
+
 // Control is a Debian control.
 type Control struct {
 	Name          string
+	Source        string
 	Version       string
 	Section       string
 	Priority      string
 	Architecture  string
+	Essential     bool
+	Protected     bool
+	MultiArch     string
 	Depends       []string
 	PreDepends    []string
 	Recommends    []string
@@ -24,10 +39,40 @@ type Control struct {
 	Enhances      []string
 	Breaks        []string
 	Conflicts     []string
+	Provides      []string
+	Replaces      []string
+	BuiltUsing    []string
 	InstalledSize int64
 	Maintainer    string
 	Description   string
 	Homepage      string
+	Custom        map[string]string
+}
+
+var controlFields = map[string]struct{}{
+	"architecture":   {},
+	"breaks":         {},
+	"built-using":    {},
+	"conflicts":      {},
+	"depends":        {},
+	"description":    {},
+	"enhances":       {},
+	"essential":      {},
+	"homepage":       {},
+	"installed-size": {},
+	"maintainer":     {},
+	"multi-arch":     {},
+	"package":        {},
+	"pre-depends":    {},
+	"priority":       {},
+	"protected":      {},
+	"provides":       {},
+	"recommends":     {},
+	"replaces":       {},
+	"section":        {},
+	"source":         {},
+	"suggests":       {},
+	"version":        {},
 }
 
 // NewControl creates a new Debian control instance.
@@ -39,59 +84,267 @@ func NewControl() *Control {
 	}
 }
 
-// String generates a Debian control data string representation.
-func (c *Control) String() string {
-	var data string
+// ParseControl parses Debian control data.
+func ParseControl(data []byte) (*Control, error) {
+	paragraphs, err := ParseParagraphs(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	} else if len(paragraphs) == 0 {
+		return nil, fmt.Errorf("%w: empty control", ErrInvalidField)
+	}
+
+	return ParseControlParagraph(paragraphs[0])
+}
+
+// ParseControlParagraph creates a new Debian control instance given a deb822 paragraph.
+func ParseControlParagraph(p *Paragraph) (*Control, error) {
+	c := &Control{}
+
+	for _, f := range p.Fields {
+		switch strings.ToLower(f.Name) {
+		case "package":
+			c.Name = f.Value
+
+		case "source":
+			c.Source = f.Value
+
+		case "version":
+			c.Version = f.Value
+
+		case "section":
+			c.Section = f.Value
+
+		case "priority":
+			c.Priority = f.Value
+
+		case "architecture":
+			c.Architecture = f.Value
+
+		case "essential":
+			c.Essential = f.Value == "yes"
+
+		case "protected":
+			c.Protected = f.Value == "yes"
+
+		case "multi-arch":
+			c.MultiArch = f.Value
+
+		case "depends":
+			c.Depends = parseDepends(f.Value)
+
+		case "pre-depends":
+			c.PreDepends = parseDepends(f.Value)
+
+		case "recommends":
+			c.Recommends = parseDepends(f.Value)
+
+		case "suggests":
+			c.Suggests = parseDepends(f.Value)
+
+		case "enhances":
+			c.Enhances = parseDepends(f.Value)
+
+		case "breaks":
+			c.Breaks = parseDepends(f.Value)
+
+		case "conflicts":
+			c.Conflicts = parseDepends(f.Value)
+
+		case "provides":
+			c.Provides = parseDepends(f.Value)
+
+		case "replaces":
+			c.Replaces = parseDepends(f.Value)
+
+		case "built-using":
+			c.BuiltUsing = parseDepends(f.Value)
+
+		case "installed-size":
+			size, err := strconv.ParseInt(f.Value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidValue, f.Value)
+			}
+			c.InstalledSize = size * 1024
+
+		case "maintainer":
+			c.Maintainer = f.Value
+
+		case "description":
+			c.Description = parseDescription(f.Value)
+
+		case "homepage":
+			c.Homepage = f.Value
+
+		default:
+			if c.Custom == nil {
+				c.Custom = map[string]string{}
+			}
+			c.Custom[f.Name] = f.Value
+		}
+	}
+
+	return c, nil
+}
+
+// Paragraph generates the deb822 paragraph of the Debian control.
+func (c *Control) Paragraph() *Paragraph {
+	p := &Paragraph{}
 
-	data += fmt.Sprintf("Package: %s\n", c.Name)
-	data += fmt.Sprintf("Version: %s\n", c.Version)
+	p.Set("Package", c.Name)
+	if c.Source != "" {
+		p.Set("Source", c.Source)
+	}
+	p.Set("Version", c.Version)
 	if c.Section != "" {
-		data += fmt.Sprintf("Section: %s\n", c.Section)
+		p.Set("Section", c.Section)
+	}
+	if c.Priority != "" {
+		p.Set("Priority", c.Priority)
+	}
+	p.Set("Architecture", c.Architecture)
+	if c.Essential {
+		p.Set("Essential", "yes")
+	}
+	if c.Protected {
+		p.Set("Protected", "yes")
+	}
+	if c.MultiArch != "" {
+		p.Set("Multi-Arch", c.MultiArch)
 	}
-	data += fmt.Sprintf("Priority: %s\n", c.Priority)
-	data += fmt.Sprintf("Architecture: %s\n", c.Architecture)
 	if len(c.Depends) > 0 {
-		data += fmt.Sprintf("Depends: %s\n", formatDepends(c.Depends))
+		p.Set("Depends", formatDepends(c.Depends))
 	}
 	if len(c.PreDepends) > 0 {
-		data += fmt.Sprintf("Pre-Depends: %s\n", formatDepends(c.PreDepends))
+		p.Set("Pre-Depends", formatDepends(c.PreDepends))
 	}
 	if len(c.Recommends) > 0 {
-		data += fmt.Sprintf("Recommends: %s\n", formatDepends(c.Recommends))
+		p.Set("Recommends", formatDepends(c.Recommends))
 	}
 	if len(c.Suggests) > 0 {
-		data += fmt.Sprintf("Suggests: %s\n", formatDepends(c.Suggests))
+		p.Set("Suggests", formatDepends(c.Suggests))
 	}
 	if len(c.Enhances) > 0 {
-		data += fmt.Sprintf("Enhances: %s\n", formatDepends(c.Enhances))
+		p.Set("Enhances", formatDepends(c.Enhances))
 	}
 	if len(c.Breaks) > 0 {
-		data += fmt.Sprintf("Breaks: %s\n", formatDepends(c.Breaks))
+		p.Set("Breaks", formatDepends(c.Breaks))
 	}
 	if len(c.Conflicts) > 0 {
-		data += fmt.Sprintf("Conflicts: %s\n", formatDepends(c.Conflicts))
+		p.Set("Conflicts", formatDepends(c.Conflicts))
+	}
+	if len(c.Provides) > 0 {
+		p.Set("Provides", formatDepends(c.Provides))
+	}
+	if len(c.Replaces) > 0 {
+		p.Set("Replaces", formatDepends(c.Replaces))
+	}
+	if len(c.BuiltUsing) > 0 {
+		p.Set("Built-Using", formatDepends(c.BuiltUsing))
 	}
 	if c.InstalledSize > 0 {
-		data += fmt.Sprintf("Installed-Size: %d\n", c.InstalledSize/1024)
+		p.Set("Installed-Size", strconv.FormatInt(c.InstalledSize/1024, 10))
 	}
 	if c.Maintainer != "" {
-		data += fmt.Sprintf("Maintainer: %s\n", c.Maintainer)
+		p.Set("Maintainer", c.Maintainer)
 	}
-	data += fmt.Sprintf("Description: %s\n", formatDescription(c.Description))
+	p.Set("Description", formatDescription(c.Description))
 	if c.Homepage != "" {
-		data += fmt.Sprintf("Homepage: %s\n", c.Homepage)
+		p.Set("Homepage", c.Homepage)
+	}
+
+	// Append custom fields sorted by name for consistent output
+	keys := []string{}
+	for key := range c.Custom {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		p.Set(key, c.Custom[key])
+	}
+
+	return p
+}
+
+// String generates a Debian control data string representation.
+func (c *Control) String() string {
+	return c.Paragraph().String()
+}
+
+// Validate checks for Debian control validity.
+func (c *Control) Validate() error {
+	switch c.MultiArch {
+	case "", MultiArchAllowed, MultiArchForeign, MultiArchNo, MultiArchSame:
+	default:
+		return fmt.Errorf("%w: unsupported Multi-Arch value %q", ErrInvalidValue, c.MultiArch)
+	}
+
+	for _, field := range []struct {
+		name  string
+		value []string
+	}{
+		{"Depends", c.Depends},
+		{"Pre-Depends", c.PreDepends},
+		{"Recommends", c.Recommends},
+		{"Suggests", c.Suggests},
+		{"Enhances", c.Enhances},
+		{"Breaks", c.Breaks},
+		{"Conflicts", c.Conflicts},
+		{"Provides", c.Provides},
+		{"Replaces", c.Replaces},
+		{"Built-Using", c.BuiltUsing},
+	} {
+		if len(field.value) == 0 {
+			continue
+		}
+
+		_, err := ParseFieldRelations(field.name, formatDepends(field.value))
+		if err != nil {
+			return fmt.Errorf("invalid %s field: %w", field.name, err)
+		}
+	}
+
+	for key, value := range c.Custom {
+		if _, ok := controlFields[strings.ToLower(key)]; ok || !ValidFieldName(key) {
+			return fmt.Errorf("%w: %q", ErrInvalidField, key)
+		} else if strings.Contains(value, "\n\n") {
+			return fmt.Errorf("%w: %q field contains an empty line", ErrInvalidValue, key)
+		}
 	}
 
-	return data
+	return nil
 }
 
 func formatDepends(depends []string) string {
 	return strings.Join(depends, ", ")
 }
 
+func parseDepends(v string) []string {
+	var depends []string
+
+	for _, dep := range strings.Split(strings.ReplaceAll(v, "\n", " "), ",") {
+		dep = strings.TrimSpace(dep)
+		if dep != "" {
+			depends = append(depends, dep)
+		}
+	}
+
+	return depends
+}
+
 func formatDescription(desc string) string {
 	desc = wordwrap.WrapString(strings.TrimSpace(desc), ControlDescriptionWrap)
 	desc = strings.Replace(desc, "\n\n", "\n.\n", -1)
-	desc = strings.Replace(desc, "\n", "\n ", -1)
 	return desc
 }
+
+func parseDescription(v string) string {
+	lines := strings.Split(v, "\n")
+	for idx, line := range lines {
+		if idx > 0 && strings.TrimSpace(line) == "." {
+			lines[idx] = ""
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
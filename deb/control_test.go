@@ -1,6 +1,7 @@
 package deb
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -342,3 +343,129 @@ Homepage: https://example.org
 	c.Homepage = "https://example.org"
 	assert.Equal(t, expected, c.String())
 }
+
+func TestParseControl(t *testing.T) {
+	c, err := ParseControl([]byte(`Package: foo
+Version: 1:1.2.3-1
+Section: admin
+Priority: optional
+Architecture: amd64
+Depends: libc6 (>= 2.17),
+ libfoo | libbar
+Installed-Size: 12
+Maintainer: Foo Bar <foo@example.org>
+Description: a short description
+ A long description.
+ .
+ Another paragraph.
+Homepage: https://example.org
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, &Control{
+		Name:          "foo",
+		Version:       "1:1.2.3-1",
+		Section:       "admin",
+		Priority:      "optional",
+		Architecture:  "amd64",
+		Depends:       []string{"libc6 (>= 2.17)", "libfoo | libbar"},
+		InstalledSize: 12 * 1024,
+		Maintainer:    "Foo Bar <foo@example.org>",
+		Description:   "a short description\nA long description.\n\nAnother paragraph.",
+		Homepage:      "https://example.org",
+	}, c)
+
+	// Ensure parsed control gets rendered back identically
+	assert.Equal(t, `Package: foo
+Version: 1:1.2.3-1
+Section: admin
+Priority: optional
+Architecture: amd64
+Depends: libc6 (>= 2.17), libfoo | libbar
+Installed-Size: 12
+Maintainer: Foo Bar <foo@example.org>
+Description: a short description
+ A long description.
+ .
+ Another paragraph.
+Homepage: https://example.org
+`, c.String())
+}
+
+func TestParseControlInvalid(t *testing.T) {
+	for _, data := range []string{
+		" continuation\n",
+		"Package foo\n",
+	} {
+		_, err := ParseControl([]byte(data))
+		assert.True(t, errors.Is(err, ErrInvalidField), data)
+	}
+
+	_, err := ParseControl([]byte("Package: foo\nInstalled-Size: foo\n"))
+	assert.True(t, errors.Is(err, ErrInvalidValue))
+}
+
+func TestControlExtended(t *testing.T) {
+	expected := `Package: foo
+Source: foo-src
+Version: 0.0.0
+Priority: extra
+Architecture: all
+Essential: yes
+Protected: yes
+Multi-Arch: foreign
+Provides: bar
+Replaces: baz (<< 1.0)
+Built-Using: golang-1.14 (= 1.14.1-1)
+Description: 
+X-Bar: bar
+X-Foo: foo
+`
+
+	c := NewControl()
+	c.Name = "foo"
+	c.Source = "foo-src"
+	c.Essential = true
+	c.Protected = true
+	c.MultiArch = MultiArchForeign
+	c.Provides = []string{"bar"}
+	c.Replaces = []string{"baz (<< 1.0)"}
+	c.BuiltUsing = []string{"golang-1.14 (= 1.14.1-1)"}
+	c.Custom = map[string]string{"X-Foo": "foo", "X-Bar": "bar"}
+	assert.Nil(t, c.Validate())
+	assert.Equal(t, expected, c.String())
+
+	parsed, err := ParseControl([]byte(expected))
+	assert.Nil(t, err)
+	assert.Equal(t, c, parsed)
+}
+
+func TestControlValidate(t *testing.T) {
+	c := NewControl()
+	c.MultiArch = "foo"
+	assert.True(t, errors.Is(c.Validate(), ErrInvalidValue))
+
+	c = NewControl()
+	c.Custom = map[string]string{"Depends": "foo"}
+	assert.True(t, errors.Is(c.Validate(), ErrInvalidField))
+
+	c = NewControl()
+	c.Custom = map[string]string{"X-Foo Bar": "foo"}
+	assert.True(t, errors.Is(c.Validate(), ErrInvalidField))
+}
+
+func TestControlValidateRelations(t *testing.T) {
+	c := NewControl()
+	c.Depends = []string{"libc6 (>= 2.17)", "foo | bar"}
+	assert.Nil(t, c.Validate())
+
+	c.Depends = []string{"libc6 (>= 2.17"}
+	assert.True(t, errors.Is(c.Validate(), ErrInvalidRelation))
+
+	c.Depends = nil
+	c.Provides = []string{"foo (>= 1.0)"}
+	assert.True(t, errors.Is(c.Validate(), ErrInvalidRelation))
+
+	c.Provides = nil
+	c.BuiltUsing = []string{"foo"}
+	assert.True(t, errors.Is(c.Validate(), ErrInvalidRelation))
+}
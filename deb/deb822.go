@@ -0,0 +1,143 @@
+package deb
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Field is a deb822 paragraph field.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Paragraph is a deb822 paragraph, keeping track of its fields order.
+//
+// Multiline values are stored with their continuation lines separated by line feeds, the leading space of each
+// continuation line being stripped.
+type Paragraph struct {
+	Fields []*Field
+}
+
+// Get returns the value of a paragraph field, matching its name case-insensitively.
+func (p *Paragraph) Get(name string) (string, bool) {
+	for _, f := range p.Fields {
+		if strings.EqualFold(f.Name, name) {
+			return f.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// Set sets the value of a paragraph field, appending it if not already present.
+func (p *Paragraph) Set(name, value string) {
+	for _, f := range p.Fields {
+		if strings.EqualFold(f.Name, name) {
+			f.Value = value
+			return
+		}
+	}
+
+	p.Fields = append(p.Fields, &Field{Name: name, Value: value})
+}
+
+// Del removes a field from the paragraph.
+func (p *Paragraph) Del(name string) {
+	for idx, f := range p.Fields {
+		if strings.EqualFold(f.Name, name) {
+			p.Fields = append(p.Fields[:idx], p.Fields[idx+1:]...)
+			return
+		}
+	}
+}
+
+// String generates the deb822 string representation of the paragraph.
+func (p *Paragraph) String() string {
+	var data string
+
+	for _, f := range p.Fields {
+		data += fmt.Sprintf("%s: %s\n", f.Name, strings.Replace(f.Value, "\n", "\n ", -1))
+	}
+
+	return data
+}
+
+// ParseParagraphs parses deb822 data from an io.Reader, returning the list of paragraphs it contains.
+func ParseParagraphs(r io.Reader) ([]*Paragraph, error) {
+	var (
+		cur   *Paragraph
+		field *Field
+		line  int
+	)
+
+	paragraphs := []*Paragraph{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+
+		switch {
+		case text == "":
+			// Blank lines separate paragraphs
+			cur, field = nil, nil
+
+		case text[0] == '#':
+			// Skip comment lines
+
+		case text[0] == ' ' || text[0] == '\t':
+			if field == nil {
+				return nil, fmt.Errorf("%w: unexpected continuation on line %d", ErrInvalidField, line)
+			}
+
+			field.Value += "\n" + text[1:]
+
+		default:
+			idx := strings.Index(text, ":")
+			if idx < 1 || !ValidFieldName(text[:idx]) {
+				return nil, fmt.Errorf("%w: malformed field on line %d", ErrInvalidField, line)
+			}
+
+			if cur == nil {
+				cur = &Paragraph{}
+				paragraphs = append(paragraphs, cur)
+			}
+
+			name := text[:idx]
+			if _, ok := cur.Get(name); ok {
+				return nil, fmt.Errorf("%w: duplicate %q field on line %d", ErrInvalidField, name, line)
+			}
+
+			field = &Field{Name: name, Value: strings.TrimSpace(text[idx+1:])}
+			cur.Fields = append(cur.Fields, field)
+		}
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return paragraphs, nil
+}
+
+// ValidFieldName checks whether or not a deb822 field name is valid.
+func ValidFieldName(name string) bool {
+	if name == "" || name[0] == '#' || name[0] == '-' {
+		return false
+	}
+
+	for _, c := range name {
+		if c <= 32 || c >= 127 || c == ':' {
+			return false
+		}
+	}
+
+	return true
+}
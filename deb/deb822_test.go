@@ -0,0 +1,93 @@
+package deb
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseParagraphs(t *testing.T) {
+	data := `# Leading comment
+Package: foo
+Version: 1.2.3
+Description: a short description
+ A long description.
+ .
+   Verbatim line.
+
+Package: bar
+# Inline comment
+Depends: foo,
+ baz
+`
+
+	paragraphs, err := ParseParagraphs(strings.NewReader(data))
+	assert.Nil(t, err)
+	assert.Len(t, paragraphs, 2)
+
+	assert.Equal(t, []*Field{
+		{Name: "Package", Value: "foo"},
+		{Name: "Version", Value: "1.2.3"},
+		{Name: "Description", Value: "a short description\nA long description.\n.\n  Verbatim line."},
+	}, paragraphs[0].Fields)
+
+	assert.Equal(t, []*Field{
+		{Name: "Package", Value: "bar"},
+		{Name: "Depends", Value: "foo,\nbaz"},
+	}, paragraphs[1].Fields)
+
+	// Ensure round-trip serialization (comments excepted)
+	assert.Equal(t, `Package: foo
+Version: 1.2.3
+Description: a short description
+ A long description.
+ .
+   Verbatim line.
+`, paragraphs[0].String())
+}
+
+func TestParseParagraphsInvalid(t *testing.T) {
+	for _, data := range []string{
+		" continuation\n",
+		"Package foo\n",
+		"-Package: foo\n",
+		"Package: foo\nPackage: bar\n",
+	} {
+		_, err := ParseParagraphs(strings.NewReader(data))
+		assert.True(t, errors.Is(err, ErrInvalidField), data)
+	}
+}
+
+func TestParagraph(t *testing.T) {
+	p := &Paragraph{}
+	p.Set("Package", "foo")
+	p.Set("Version", "1.2.3")
+	p.Set("package", "bar")
+
+	v, ok := p.Get("PACKAGE")
+	assert.True(t, ok)
+	assert.Equal(t, "bar", v)
+
+	p.Del("Package")
+
+	_, ok = p.Get("Package")
+	assert.False(t, ok)
+	assert.Equal(t, "Version: 1.2.3\n", p.String())
+}
+
+func TestValidFieldName(t *testing.T) {
+	for name, expected := range map[string]bool{
+		"Package":  true,
+		"X-Foo":    true,
+		"":         false,
+		"#Foo":     false,
+		"-Foo":     false,
+		"Foo Bar":  false,
+		"Foo:Bar":  false,
+		"Foo\tBar": false,
+	} {
+		assert.Equal(t, expected, ValidFieldName(name), name)
+	}
+}
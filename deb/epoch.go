@@ -0,0 +1,25 @@
+package deb
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// SourceDateEpoch returns the time defined by the SOURCE_DATE_EPOCH environment variable, whether it is set.
+//
+// See https://reproducible-builds.org/specs/source-date-epoch/ for details.
+func SourceDateEpoch() (time.Time, bool, error) {
+	v, ok := os.LookupEnv("SOURCE_DATE_EPOCH")
+	if !ok || v == "" {
+		return time.Time{}, false, nil
+	}
+
+	sec, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || sec < 0 {
+		return time.Time{}, false, fmt.Errorf("invalid SOURCE_DATE_EPOCH value %q: %w", v, ErrInvalidValue)
+	}
+
+	return time.Unix(sec, 0).UTC(), true, nil
+}
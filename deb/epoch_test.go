@@ -0,0 +1,30 @@
+package deb
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSourceDateEpoch(t *testing.T) {
+	defer os.Unsetenv("SOURCE_DATE_EPOCH")
+
+	os.Unsetenv("SOURCE_DATE_EPOCH")
+	_, ok, err := SourceDateEpoch()
+	assert.False(t, ok)
+	assert.Nil(t, err)
+
+	os.Setenv("SOURCE_DATE_EPOCH", "1577934245")
+	v, ok, err := SourceDateEpoch()
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), v)
+
+	os.Setenv("SOURCE_DATE_EPOCH", "invalid")
+	_, ok, err = SourceDateEpoch()
+	assert.False(t, ok)
+	assert.True(t, errors.Is(err, ErrInvalidValue))
+}
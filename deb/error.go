@@ -5,6 +5,14 @@ import "errors"
 var (
 	// ErrInvalidField is an invalid field error.
 	ErrInvalidField = errors.New("invalid field")
+	// ErrInvalidPackage is an invalid package error.
+	ErrInvalidPackage = errors.New("invalid package")
+	// ErrInvalidRelation is an invalid relation error.
+	ErrInvalidRelation = errors.New("invalid relation")
 	// ErrInvalidValue is an invalid value error.
 	ErrInvalidValue = errors.New("invalid value")
+	// ErrInvalidVersion is an invalid version error.
+	ErrInvalidVersion = errors.New("invalid version")
+	// ErrMissingPrivateKey is a missing private key error.
+	ErrMissingPrivateKey = errors.New("missing private key")
 )
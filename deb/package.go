@@ -5,8 +5,11 @@ import (
 	"crypto/md5"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,49 +17,136 @@ import (
 	"mkdeb.sh/archive"
 )
 
+const debianBinary = "2.0\n"
+
+// Now returns the current time, used for packages timestamps unless being reproducible. It might be replaced to
+// build packages as if at another time.
+var Now = time.Now
+
+// Compression is a package member compression setting.
+type Compression struct {
+	Format int
+	Level  int
+}
+
+// ParseCompression parses a "FORMAT[:LEVEL]" compression setting string (e.g. "zstd:19").
+func ParseCompression(s string) (Compression, error) {
+	var (
+		c   Compression
+		err error
+	)
+
+	parts := strings.SplitN(s, ":", 2)
+
+	c.Format, err = archive.ParseCompress(parts[0])
+	if err != nil {
+		return c, fmt.Errorf("%w: %q", err, parts[0])
+	}
+
+	if len(parts) == 2 {
+		c.Level, err = strconv.Atoi(parts[1])
+		if err != nil || c.Level < 1 {
+			return c, fmt.Errorf("%w: %q", archive.ErrUnsupportedLevel, parts[1])
+		}
+	}
+
+	// Ensure compression is supported for writing
+	w, err := archive.NewCompressWriter(ioutil.Discard, c.Format, c.Level)
+	if err != nil {
+		return c, fmt.Errorf("%w: %q", err, s)
+	}
+	w.Close()
+
+	return c, nil
+}
+
+// String returns the string representation of the compression setting.
+func (c Compression) String() string {
+	s := archive.CompressName(c.Format)
+	if c.Level != archive.LevelDefault {
+		s += ":" + strconv.Itoa(c.Level)
+	}
+
+	return s
+}
+
 // Package is a Debian package.
 type Package struct {
 	Name    string
 	Arch    string
 	Version *Version
 	Control *Control
+	Signer  Signer
+
+	// ControlCompression and DataCompression are the compression settings of the control and data archives
+	// members, applied when the package gets written.
+	ControlCompression Compression
+	DataCompression    Compression
+
+	// TempDir is the directory in which the data archive gets spooled (default temporary directory if empty).
+	TempDir string
+
+	modTime      time.Time
+	reproducible bool
+	dirs         map[string]struct{}
+	control      *archive.WriterBuffer
+	data         *archive.Writer
+	dataFile     *os.File
+	spool        *os.File
+	spoolSize    int64
+	entries      []*entry
+	md5sums      *bytes.Buffer
+	confFiles    []string
+	writer       *ar.Writer
+	output       io.Writer
+}
 
-	modTime   time.Time
-	dirs      map[string]struct{}
-	control   *archive.WriterBuffer
-	data      *archive.WriterBuffer
-	md5sums   *bytes.Buffer
-	confFiles []string
-	writer    *ar.Writer
+type entry struct {
+	header *archive.Header
+	offset int64
+	size   int64
 }
 
 // NewPackage creates a new Debian package instance.
 func NewPackage(name, arch, version string, epoch uint, revision int) (*Package, error) {
-	// Initialize archives that will receive internal package data
-	control, err := archive.NewWriterBuffer(archive.CompressGzip)
+	v := NewVersion(epoch, version, fmt.Sprintf("1~mkdeb%d", revision))
+
+	// Ensure resulting version is a legal Debian package version
+	_, err := ParseVersion(v.String())
 	if err != nil {
-		return nil, fmt.Errorf("cannot create control archive: %w", err)
+		return nil, err
 	}
 
-	data, err := archive.NewWriterBuffer(archive.CompressXZ)
+	// Initialize archive that will receive internal control data, compression being applied at write time. Data
+	// archive gets lazily spooled to a temporary file to keep memory usage bounded.
+	control, err := archive.NewWriterBuffer(archive.CompressNone)
 	if err != nil {
-		return nil, fmt.Errorf("cannot create data archive: %w", err)
+		return nil, fmt.Errorf("cannot create control archive: %w", err)
 	}
 
 	return &Package{
 		Name:    name,
 		Arch:    arch,
-		Version: NewVersion(epoch, version, fmt.Sprintf("1~mkdeb%d", revision)),
+		Version: v,
 		Control: NewControl(),
 
-		modTime: time.Now(),
+		ControlCompression: Compression{Format: archive.CompressGzip},
+		DataCompression:    Compression{Format: archive.CompressXZ},
+
+		modTime: Now(),
 		dirs:    map[string]struct{}{},
 		control: control,
-		data:    data,
 		md5sums: bytes.NewBuffer(nil),
 	}, nil
 }
 
+// SetReproducible enables the reproducible mode, in which every timestamp is clamped to the given modification time
+// and data archive entries are sorted by name when the package gets written.
+func (p *Package) SetReproducible(modTime time.Time) {
+	p.modTime = modTime.UTC().Truncate(time.Second)
+	p.reproducible = true
+}
+
 // AddControlFile appends a new file to the internal control archive.
 func (p *Package) AddControlFile(name string, r io.Reader, fi os.FileInfo) error {
 	err := p.control.WriteHeader(&archive.Header{
@@ -65,7 +155,7 @@ func (p *Package) AddControlFile(name string, r io.Reader, fi os.FileInfo) error
 		Mode:    fi.Mode(),
 		User:    "root",
 		Group:   "root",
-		ModTime: fi.ModTime(),
+		ModTime: p.clampTime(fi.ModTime()),
 	})
 	if err != nil {
 		return err
@@ -84,7 +174,7 @@ func (p *Package) AddDir(path string, mode os.FileMode) error {
 
 	p.dirs[path] = struct{}{}
 
-	return p.data.WriteHeader(&archive.Header{
+	return p.writeHeader(&archive.Header{
 		Name:    "." + strings.TrimRight(path, "/") + "/",
 		Mode:    mode | os.ModeDir,
 		User:    "root",
@@ -106,14 +196,41 @@ func (p *Package) AddFile(path string, r io.Reader, fi os.FileInfo) error {
 	size := fi.Size()
 	p.Control.InstalledSize += size
 
-	err = p.data.WriteHeader(&archive.Header{
+	h := &archive.Header{
 		Name:    "." + path,
 		Size:    size,
 		Mode:    fi.Mode(),
 		User:    "root",
 		Group:   "root",
-		ModTime: fi.ModTime(),
-	})
+		ModTime: p.clampTime(fi.ModTime()),
+	}
+
+	// Keep file content aside in reproducible mode, as entries will only be written once sorted
+	if p.reproducible {
+		if p.spool == nil {
+			p.spool, err = ioutil.TempFile(p.TempDir, "mkdeb-spool-*")
+			if err != nil {
+				return fmt.Errorf("cannot create spool file: %w", err)
+			}
+		}
+
+		n, err := io.Copy(p.spool, r)
+		if err != nil {
+			return err
+		}
+
+		p.entries = append(p.entries, &entry{header: h, offset: p.spoolSize, size: n})
+		p.spoolSize += n
+
+		return nil
+	}
+
+	err = p.initData()
+	if err != nil {
+		return err
+	}
+
+	err = p.data.WriteHeader(h)
 	if err != nil {
 		return err
 	}
@@ -135,7 +252,7 @@ func (p *Package) AddLink(dst, src string) error {
 		return err
 	}
 
-	return p.data.WriteHeader(&archive.Header{
+	return p.writeHeader(&archive.Header{
 		Name:     "." + dst,
 		LinkName: src,
 		Mode:     os.FileMode(0777) | os.ModeSymlink,
@@ -150,6 +267,26 @@ func (p *Package) RegisterConfFile(path string) {
 	p.confFiles = append(p.confFiles, path)
 }
 
+// Close removes the package temporary files. It is automatically called once the package has been written.
+func (p *Package) Close() error {
+	for _, f := range []*os.File{p.dataFile, p.spool} {
+		if f == nil {
+			continue
+		}
+
+		f.Close()
+
+		err := os.Remove(f.Name())
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	p.dataFile, p.spool = nil, nil
+
+	return nil
+}
+
 // Write write the content of the Debian package to a given io.Writer.
 func (p *Package) Write(w io.Writer) error {
 	var (
@@ -157,7 +294,32 @@ func (p *Package) Write(w io.Writer) error {
 		err error
 	)
 
-	now := time.Now()
+	defer p.Close()
+
+	err = p.Control.Validate()
+	if err != nil {
+		return fmt.Errorf("invalid control: %w", err)
+	}
+
+	now := Now()
+	if p.reproducible {
+		now = p.modTime
+	}
+
+	err = p.initData()
+	if err != nil {
+		return err
+	}
+
+	// Flush sorted data entries if deferred
+	if p.reproducible {
+		err = p.flushEntries()
+		if err != nil {
+			return fmt.Errorf("cannot write data entries: %w", err)
+		}
+
+		sort.Strings(p.confFiles)
+	}
 
 	// Add generated control files
 	p.Control.Name = p.Name
@@ -195,28 +357,206 @@ func (p *Package) Write(w io.Writer) error {
 		return fmt.Errorf("cannot close data archive: %w", err)
 	}
 
+	control, err := compressMember(p.control.Bytes(), p.ControlCompression)
+	if err != nil {
+		return fmt.Errorf("cannot compress control archive: %w", err)
+	}
+
+	data, dataSize, err := p.compressData()
+	if err != nil {
+		return fmt.Errorf("cannot compress data archive: %w", err)
+	}
+	defer func() {
+		data.Close()
+		os.Remove(data.Name())
+	}()
+
 	// Initialize archive file and append content
+	p.output = w
 	p.writer = ar.NewWriter(w)
 	err = p.writer.WriteGlobalHeader()
 	if err != nil {
 		return fmt.Errorf("cannot write archive header: %w", err)
 	}
 
-	err = p.append("debian-binary", []byte("2.0\n"), now)
+	err = p.append("debian-binary", strings.NewReader(debianBinary), int64(len(debianBinary)), now)
 	if err != nil {
 		return fmt.Errorf("cannot append debian-binary: %w", err)
 	}
 
-	err = p.append("control.tar.gz", p.control.Bytes(), now)
+	name := "control.tar" + archive.CompressExt(p.ControlCompression.Format)
+
+	err = p.append(name, bytes.NewReader(control), int64(len(control)), now)
+	if err != nil {
+		return fmt.Errorf("cannot append %s: %w", name, err)
+	}
+
+	name = "data.tar" + archive.CompressExt(p.DataCompression.Format)
+
+	err = p.append(name, io.NewSectionReader(data, 0, dataSize), dataSize, now)
+	if err != nil {
+		return fmt.Errorf("cannot append %s: %w", name, err)
+	}
+
+	// Sign package members if a signer has been provided (see debsigs(1) for details)
+	if p.Signer != nil {
+		sig := bytes.NewBuffer(nil)
+
+		err = p.Signer.Sign(sig, io.MultiReader(
+			strings.NewReader(debianBinary),
+			bytes.NewReader(control),
+			io.NewSectionReader(data, 0, dataSize),
+		))
+		if err != nil {
+			return fmt.Errorf("cannot sign package: %w", err)
+		}
+
+		err = p.append("_gpgorigin", sig, int64(sig.Len()), now)
+		if err != nil {
+			return fmt.Errorf("cannot append _gpgorigin: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func compressMember(b []byte, c Compression) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+
+	w, err := archive.NewCompressWriter(buf, c.Format, c.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = w.Write(b)
 	if err != nil {
-		return fmt.Errorf("cannot append control.tar.gz: %w", err)
+		return nil, err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// compressData compresses the spooled data archive into a new temporary file, returning it along with its size.
+func (p *Package) compressData() (*os.File, int64, error) {
+	_, err := p.dataFile.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	f, err := ioutil.TempFile(p.TempDir, "mkdeb-data-*.tar"+archive.CompressExt(p.DataCompression.Format))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = func() error {
+		w, err := archive.NewCompressWriter(f, p.DataCompression.Format, p.DataCompression.Level)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(w, p.dataFile)
+		if err != nil {
+			return err
+		}
+
+		return w.Close()
+	}()
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, 0, err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, 0, err
+	}
+
+	return f, fi.Size(), nil
+}
+
+// initData lazily initializes the data archive, spooled to a temporary file.
+func (p *Package) initData() error {
+	if p.data != nil {
+		return nil
 	}
 
-	err = p.append("data.tar.xz", p.data.Bytes(), now)
+	f, err := ioutil.TempFile(p.TempDir, "mkdeb-data-*.tar")
 	if err != nil {
-		return fmt.Errorf("cannot append data.tar.xz: %w", err)
+		return fmt.Errorf("cannot create data archive: %w", err)
 	}
 
+	p.data, err = archive.NewWriter(f, archive.CompressNone)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return fmt.Errorf("cannot create data archive: %w", err)
+	}
+
+	p.dataFile = f
+
+	return nil
+}
+
+func (p *Package) clampTime(t time.Time) time.Time {
+	if p.reproducible && (t.IsZero() || t.After(p.modTime)) {
+		return p.modTime
+	}
+
+	return t
+}
+
+func (p *Package) writeHeader(h *archive.Header) error {
+	if p.reproducible {
+		p.entries = append(p.entries, &entry{header: h})
+		return nil
+	}
+
+	err := p.initData()
+	if err != nil {
+		return err
+	}
+
+	return p.data.WriteHeader(h)
+}
+
+func (p *Package) flushEntries() error {
+	sort.SliceStable(p.entries, func(i, j int) bool {
+		return p.entries[i].header.Name < p.entries[j].header.Name
+	})
+
+	for _, e := range p.entries {
+		// Check for file type prior to writing header, as its mode gets altered by the archive writer
+		regular := e.header.Mode.IsRegular()
+
+		err := p.data.WriteHeader(e.header)
+		if err != nil {
+			return err
+		}
+
+		if !regular {
+			continue
+		}
+
+		digest := md5.New()
+
+		_, err = io.Copy(io.MultiWriter(p.data, digest), io.NewSectionReader(p.spool, e.offset, e.size))
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(p.md5sums, "%x  %s\n", digest.Sum(nil), e.header.Name[2:])
+	}
+
+	p.entries = nil
+
 	return nil
 }
 
@@ -235,17 +575,28 @@ func (p *Package) ensureParent(path string) error {
 	return nil
 }
 
-func (p *Package) append(name string, b []byte, modTime time.Time) error {
+func (p *Package) append(name string, r io.Reader, size int64, modTime time.Time) error {
 	err := p.writer.WriteHeader(&ar.Header{
 		Name:    name,
 		ModTime: modTime,
 		Mode:    0644,
-		Size:    int64(len(b)),
+		Size:    size,
 	})
 	if err != nil {
 		return err
 	}
 
-	_, err = p.writer.Write(b)
+	// Copy member content straight to the output as the archive writer only supports padding single writes
+	n, err := io.Copy(p.output, r)
+	if err != nil {
+		return err
+	} else if n != size {
+		return fmt.Errorf("unexpected %q member size", name)
+	}
+
+	if size%2 == 1 {
+		_, err = p.output.Write([]byte{'\n'})
+	}
+
 	return err
 }
@@ -1,6 +1,11 @@
 package deb
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"strings"
@@ -8,6 +13,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"mkdeb.sh/archive"
 )
 
 var testPkg *Package
@@ -22,6 +28,12 @@ func TestPackage(t *testing.T) {
 	assert.Equal(t, &Version{0, "1.2.3", "1~mkdeb1"}, testPkg.Version)
 }
 
+func TestPackageInvalidVersion(t *testing.T) {
+	p, err := NewPackage("foo", "all", "v1.2.3", 0, 1)
+	assert.Nil(t, p)
+	assert.True(t, errors.Is(err, ErrInvalidVersion))
+}
+
 func TestPackageAddControlFile(t *testing.T) {
 	err := testPkg.AddControlFile(
 		"postinst",
@@ -36,7 +48,7 @@ func TestPackageAddDir(t *testing.T) {
 	err := testPkg.AddDir("/path/to/dir", os.FileMode(0755))
 	assert.Nil(t, err)
 	assert.Contains(t, testPkg.dirs, "/path/to/dir")
-	assert.True(t, len(testPkg.data.Bytes()) > 0)
+	assert.True(t, testDataSize(t) > 0)
 	assert.Equal(t, int64(0), testPkg.Control.InstalledSize)
 }
 
@@ -47,17 +59,24 @@ func TestPackageAddFile(t *testing.T) {
 		newFileInfo("/path/to/file", 7, os.FileMode(0644), time.Now(), false),
 	)
 	assert.Nil(t, err)
-	assert.True(t, len(testPkg.data.Bytes()) > 0)
+	assert.True(t, testDataSize(t) > 0)
 	assert.Equal(t, int64(7), testPkg.Control.InstalledSize)
 }
 
 func TestPackageAddLink(t *testing.T) {
 	err := testPkg.AddLink("/path/to/link", "/path/to/target")
 	assert.Nil(t, err)
-	assert.True(t, len(testPkg.data.Bytes()) > 0)
+	assert.True(t, testDataSize(t) > 0)
 	assert.Equal(t, int64(7), testPkg.Control.InstalledSize)
 }
 
+func testDataSize(t *testing.T) int64 {
+	fi, err := testPkg.dataFile.Stat()
+	assert.Nil(t, err)
+
+	return fi.Size()
+}
+
 func TestPackageRegisterConfFile(t *testing.T) {
 	testPkg.RegisterConfFile("/path/to/conffile")
 	assert.Contains(t, testPkg.confFiles, "/path/to/conffile")
@@ -67,3 +86,149 @@ func TestPackageWrite(t *testing.T) {
 	err := testPkg.Write(ioutil.Discard)
 	assert.Nil(t, err)
 }
+
+func TestPackageReproducible(t *testing.T) {
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	buildTime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// Build the same package twice at different times, adding its content in a different order and with different
+	// files times
+	first := testReproduciblePackage(t, modTime, buildTime, false)
+	second := testReproduciblePackage(t, modTime, buildTime.Add(time.Hour), true)
+
+	assert.Equal(t, sha256.Sum256(first), sha256.Sum256(second))
+}
+
+func TestPackageReproducibleClamp(t *testing.T) {
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	before := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	p, err := NewPackage("foo", "all", "1.2.3", 0, 1)
+	assert.Nil(t, err)
+	p.SetReproducible(modTime)
+
+	assert.Equal(t, modTime, p.clampTime(time.Now()))
+	assert.Equal(t, modTime, p.clampTime(time.Time{}))
+	assert.Equal(t, before, p.clampTime(before))
+}
+
+func testReproduciblePackage(t *testing.T, modTime, buildTime time.Time, reverse bool) []byte {
+	Now = func() time.Time { return buildTime }
+	defer func() { Now = time.Now }()
+
+	p, err := NewPackage("foo", "amd64", "1.2.3", 0, 1)
+	assert.Nil(t, err)
+	p.SetReproducible(modTime)
+
+	steps := []func() error{
+		func() error {
+			return p.AddFile("/usr/bin/foo", strings.NewReader("foo\n"),
+				newFileInfo("foo", 4, 0755, buildTime, false))
+		},
+		func() error {
+			return p.AddFile("/usr/share/doc/foo/README", strings.NewReader("bar\n"),
+				newFileInfo("README", 4, 0644, buildTime, false))
+		},
+		func() error {
+			return p.AddLink("/usr/bin/bar", "/usr/bin/foo")
+		},
+		func() error {
+			return p.AddDir("/var/lib/foo", 0755)
+		},
+	}
+
+	if reverse {
+		for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+			steps[i], steps[j] = steps[j], steps[i]
+		}
+	}
+
+	for _, step := range steps {
+		assert.Nil(t, step())
+	}
+
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, p.Write(buf))
+
+	return buf.Bytes()
+}
+
+func TestPackageCompression(t *testing.T) {
+	p, err := NewPackage("foo", "all", "1.2.3", 0, 1)
+	assert.Nil(t, err)
+	p.ControlCompression = Compression{Format: archive.CompressNone}
+	p.DataCompression = Compression{Format: archive.CompressZstd, Level: 19}
+
+	err = p.AddFile("/usr/bin/foo", strings.NewReader("foo\n"), newFileInfo("foo", 4, 0755, time.Now(), false))
+	assert.Nil(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, p.Write(buf))
+
+	r, err := NewReader(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "control.tar", r.Members[1].Name)
+	assert.Equal(t, "data.tar.zst", r.Members[2].Name)
+	assert.Equal(t, "foo", r.Control.Name)
+	assert.Equal(t, "./usr/bin/foo", r.Files[len(r.Files)-1].Name)
+}
+
+func TestParseCompression(t *testing.T) {
+	for input, expected := range map[string]Compression{
+		"none":    {Format: archive.CompressNone},
+		"gzip":    {Format: archive.CompressGzip},
+		"gzip:9":  {Format: archive.CompressGzip, Level: 9},
+		"xz:6":    {Format: archive.CompressXZ, Level: 6},
+		"zstd:19": {Format: archive.CompressZstd, Level: 19},
+	} {
+		c, err := ParseCompression(input)
+		assert.Nil(t, err, input)
+		assert.Equal(t, expected, c, input)
+		assert.Equal(t, input, c.String())
+	}
+
+	for _, input := range []string{"", "foo", "bzip2", "none:1", "gzip:0", "gzip:10", "zstd:foo"} {
+		_, err := ParseCompression(input)
+		assert.NotNil(t, err, input)
+	}
+}
+
+func BenchmarkPackageWrite(b *testing.B) {
+	// Memory usage should remain flat whatever the size of the packaged content, as data gets spooled on disk
+	for _, size := range []int64{16 << 20, 64 << 20, 256 << 20} {
+		b.Run(fmt.Sprintf("%dMiB", size>>20), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(size)
+
+			for i := 0; i < b.N; i++ {
+				p, err := NewPackage("foo", "all", "1.2.3", 0, 1)
+				if err != nil {
+					b.Fatal(err)
+				}
+				p.DataCompression = Compression{Format: archive.CompressGzip, Level: 1}
+
+				err = p.AddFile("/usr/share/foo/data", io.LimitReader(zeroReader{}, size),
+					newFileInfo("data", size, 0644, time.Now(), false))
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				err = p.Write(ioutil.Discard)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(b []byte) (int, error) {
+	for i := range b {
+		b[i] = 0
+	}
+
+	return len(b), nil
+}
@@ -0,0 +1,230 @@
+package deb
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/blakesmith/ar"
+	"mkdeb.sh/archive"
+)
+
+// Member is a Debian package archive member.
+type Member struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Reader is a Debian package reader.
+type Reader struct {
+	Members      []*Member
+	Control      *Control
+	ControlFiles []*archive.Header
+	ConfFiles    []string
+	MD5Sums      map[string]string
+	Files        []*archive.Header
+
+	controlData []byte
+}
+
+// Open opens and parses a Debian package given its path.
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return NewReader(f)
+}
+
+// NewReader parses a Debian package given an io.Reader.
+func NewReader(r io.Reader) (*Reader, error) {
+	var err error
+
+	pr := &Reader{
+		MD5Sums: map[string]string{},
+	}
+
+	rd := ar.NewReader(r)
+	for {
+		h, err := rd.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("cannot read archive: %w", err)
+		}
+
+		name := strings.TrimRight(h.Name, "/")
+
+		// Ensure package starts with a supported format version
+		if len(pr.Members) == 0 {
+			if name != "debian-binary" {
+				return nil, fmt.Errorf("%w: missing debian-binary", ErrInvalidPackage)
+			}
+
+			b, err := ioutil.ReadAll(rd)
+			if err != nil {
+				return nil, err
+			} else if !strings.HasPrefix(string(b), "2.") {
+				return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidPackage, strings.TrimSpace(string(b)))
+			}
+		}
+
+		pr.Members = append(pr.Members, &Member{
+			Name:    name,
+			Size:    h.Size,
+			ModTime: h.ModTime,
+		})
+
+		switch {
+		case strings.HasPrefix(name, "control.tar"):
+			err = pr.readControl(rd, name)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read %s: %w", name, err)
+			}
+
+		case strings.HasPrefix(name, "data.tar"):
+			err = pr.readData(rd, name)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read %s: %w", name, err)
+			}
+		}
+	}
+
+	if len(pr.Members) == 0 {
+		return nil, fmt.Errorf("%w: empty archive", ErrInvalidPackage)
+	} else if pr.controlData == nil {
+		return nil, fmt.Errorf("%w: missing control file", ErrInvalidPackage)
+	} else if pr.Files == nil {
+		return nil, fmt.Errorf("%w: missing data archive", ErrInvalidPackage)
+	}
+
+	pr.Control, err = ParseControl(pr.controlData)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse control: %w", err)
+	}
+
+	return pr, nil
+}
+
+func (r *Reader) readControl(rd io.Reader, name string) error {
+	compress, err := memberCompress(name)
+	if err != nil {
+		return err
+	}
+
+	tr, err := archive.NewReader(rd, compress)
+	if err != nil {
+		return err
+	}
+	defer tr.Close()
+
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		r.ControlFiles = append(r.ControlFiles, h)
+
+		switch path.Clean(h.Name) {
+		case "control":
+			r.controlData, err = ioutil.ReadAll(tr)
+			if err != nil {
+				return err
+			}
+
+		case "conffiles":
+			scanner := bufio.NewScanner(tr)
+			for scanner.Scan() {
+				if line := strings.TrimSpace(scanner.Text()); line != "" {
+					r.ConfFiles = append(r.ConfFiles, line)
+				}
+			}
+
+			err = scanner.Err()
+			if err != nil {
+				return err
+			}
+
+		case "md5sums":
+			scanner := bufio.NewScanner(tr)
+			for scanner.Scan() {
+				parts := strings.Fields(scanner.Text())
+				if len(parts) == 2 {
+					r.MD5Sums[parts[1]] = parts[0]
+				}
+			}
+
+			err = scanner.Err()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *Reader) readData(rd io.Reader, name string) error {
+	compress, err := memberCompress(name)
+	if err != nil {
+		return err
+	}
+
+	tr, err := archive.NewReader(rd, compress)
+	if err != nil {
+		return err
+	}
+	defer tr.Close()
+
+	r.Files = []*archive.Header{}
+
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		r.Files = append(r.Files, h)
+	}
+
+	return nil
+}
+
+// ControlData returns the raw control file data.
+func (r *Reader) ControlData() []byte {
+	return r.controlData
+}
+
+func memberCompress(name string) (int, error) {
+	switch path.Ext(name) {
+	case ".tar":
+		return archive.CompressNone, nil
+
+	case ".bz2":
+		return archive.CompressBzip2, nil
+
+	case ".gz":
+		return archive.CompressGzip, nil
+
+	case ".xz":
+		return archive.CompressXZ, nil
+
+	case ".zst":
+		return archive.CompressZstd, nil
+	}
+
+	return 0, archive.ErrUnsupportedCompress
+}
@@ -0,0 +1,81 @@
+package deb
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReader(t *testing.T) {
+	now := time.Now()
+
+	p, err := NewPackage("foo", "amd64", "1.2.3", 0, 1)
+	assert.Nil(t, err)
+	p.Control.Description = "Foo package"
+	p.Control.Depends = []string{"libc6"}
+
+	err = p.AddFile("/etc/foo.conf", strings.NewReader("foo=bar\n"), newFileInfo("foo.conf", 8, 0644, now, false))
+	assert.Nil(t, err)
+	p.RegisterConfFile("/etc/foo.conf")
+
+	err = p.AddFile("/usr/bin/foo", strings.NewReader("foo\n"), newFileInfo("foo", 4, 0755, now, false))
+	assert.Nil(t, err)
+
+	err = p.AddLink("/usr/bin/bar", "foo")
+	assert.Nil(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, p.Write(buf))
+
+	r, err := NewReader(buf)
+	assert.Nil(t, err)
+
+	names := []string{}
+	for _, m := range r.Members {
+		names = append(names, m.Name)
+	}
+	assert.Equal(t, []string{"debian-binary", "control.tar.gz", "data.tar.xz"}, names)
+
+	assert.Equal(t, "foo", r.Control.Name)
+	assert.Equal(t, "1.2.3-1~mkdeb1", r.Control.Version)
+	assert.Equal(t, "amd64", r.Control.Architecture)
+	assert.Equal(t, []string{"libc6"}, r.Control.Depends)
+	assert.Equal(t, "Foo package", r.Control.Description)
+	assert.Contains(t, string(r.ControlData()), "Package: foo\n")
+
+	assert.Equal(t, []string{"/etc/foo.conf"}, r.ConfFiles)
+	assert.Equal(t, map[string]string{
+		"etc/foo.conf": "14a7f05778753dec84782c623292a5f2",
+		"usr/bin/foo":  "d3b07384d113edec49eaa6238ad5ff00",
+	}, r.MD5Sums)
+
+	files := map[string]string{}
+	for _, h := range r.Files {
+		files[h.Name] = h.LinkName
+		if h.Name == "./usr/bin/foo" {
+			assert.Equal(t, os.FileMode(0755), h.Mode)
+			assert.Equal(t, int64(4), h.Size)
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"./etc/":         "",
+		"./etc/foo.conf": "",
+		"./usr/":         "",
+		"./usr/bin/":     "",
+		"./usr/bin/bar":  "foo",
+		"./usr/bin/foo":  "",
+	}, files)
+}
+
+func TestReaderInvalid(t *testing.T) {
+	_, err := NewReader(strings.NewReader("foo"))
+	assert.NotNil(t, err)
+
+	_, err = NewReader(strings.NewReader("!<arch>\n"))
+	assert.True(t, errors.Is(err, ErrInvalidPackage))
+}
@@ -0,0 +1,285 @@
+package deb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Relation operators:
+const (
+	RelationEarlier        = "<<"
+	RelationEarlierOrEqual = "<="
+	RelationEqual          = "="
+	RelationLaterOrEqual   = ">="
+	RelationLater          = ">>"
+)
+
+// Dependency is a single package dependency.
+type Dependency struct {
+	Name          string
+	ArchQualifier string
+	Operator      string
+	Version       string
+	Archs         []string
+	Profiles      [][]string
+}
+
+// String generates the string representation of the dependency.
+func (d *Dependency) String() string {
+	s := d.Name
+	if d.ArchQualifier != "" {
+		s += ":" + d.ArchQualifier
+	}
+	if d.Operator != "" {
+		s += fmt.Sprintf(" (%s %s)", d.Operator, d.Version)
+	}
+	if len(d.Archs) > 0 {
+		s += " [" + strings.Join(d.Archs, " ") + "]"
+	}
+	for _, profiles := range d.Profiles {
+		s += " <" + strings.Join(profiles, " ") + ">"
+	}
+
+	return s
+}
+
+// Relation is a list of alternative dependencies.
+type Relation []*Dependency
+
+// String generates the string representation of the relation.
+func (r Relation) String() string {
+	parts := []string{}
+	for _, d := range r {
+		parts = append(parts, d.String())
+	}
+
+	return strings.Join(parts, " | ")
+}
+
+// ParseRelations parses a comma-separated list of relations (e.g. a "Depends" field value).
+func ParseRelations(s string) ([]Relation, error) {
+	relations := []Relation{}
+
+	for _, part := range strings.Split(s, ",") {
+		rel, err := ParseRelation(part)
+		if err != nil {
+			return nil, err
+		}
+
+		relations = append(relations, rel)
+	}
+
+	return relations, nil
+}
+
+// ParseFieldRelations parses a comma-separated list of relations of the given control field, enforcing its own
+// restrictions: "Provides" relations only allow the "=" operator, without architecture restrictions nor build
+// profiles, and "Built-Using" relations require an exact "=" version.
+func ParseFieldRelations(field, s string) ([]Relation, error) {
+	relations, err := ParseRelations(s)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rel := range relations {
+		for _, d := range rel {
+			var reason string
+
+			switch {
+			case strings.EqualFold(field, "Provides"):
+				if d.Operator != "" && d.Operator != RelationEqual {
+					reason = fmt.Sprintf("version operator %q not allowed, only %q is", d.Operator, RelationEqual)
+				} else if len(d.Archs) > 0 {
+					reason = "architecture restrictions not allowed"
+				} else if len(d.Profiles) > 0 {
+					reason = "build profiles not allowed"
+				}
+
+			case strings.EqualFold(field, "Built-Using"):
+				if d.Operator != RelationEqual {
+					reason = fmt.Sprintf("exact %q version required", RelationEqual)
+				}
+			}
+
+			if reason != "" {
+				return nil, fmt.Errorf("%w: %q: %s", ErrInvalidRelation, d.String(), reason)
+			}
+		}
+	}
+
+	return relations, nil
+}
+
+// ParseRelation parses a single relation, made of one or more "|"-separated alternative dependencies.
+func ParseRelation(s string) (Relation, error) {
+	rel := Relation{}
+
+	for _, part := range strings.Split(s, "|") {
+		d, err := ParseDependency(part)
+		if err != nil {
+			return nil, err
+		}
+
+		rel = append(rel, d)
+	}
+
+	return rel, nil
+}
+
+// ParseDependency parses a single package dependency.
+//
+// Dependencies follow the "name[:arch] [(op version)] [[archs]] [<profiles>...]" syntax. The deprecated "<" and
+// ">" operators are rejected, as their meaning is ambiguous ("<=" and ">=" respectively).
+func ParseDependency(s string) (*Dependency, error) {
+	p := &relationParser{input: strings.TrimSpace(s)}
+
+	d, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %s", ErrInvalidRelation, strings.TrimSpace(s), err)
+	}
+
+	return d, nil
+}
+
+type relationParser struct {
+	input string
+	pos   int
+}
+
+func (p *relationParser) parse() (*Dependency, error) {
+	d := &Dependency{}
+
+	d.Name = p.token(isPackageNameChar)
+	if !validPackageName(d.Name) {
+		return nil, fmt.Errorf("invalid package name %q", d.Name)
+	}
+
+	if p.accept(':') {
+		d.ArchQualifier = p.token(isArchChar)
+		if d.ArchQualifier == "" {
+			return nil, fmt.Errorf("missing architecture qualifier")
+		}
+	}
+
+	p.skipSpaces()
+
+	if p.accept('(') {
+		p.skipSpaces()
+
+		d.Operator = p.token(func(c byte) bool { return c == '<' || c == '=' || c == '>' })
+		switch d.Operator {
+		case RelationEarlier, RelationEarlierOrEqual, RelationEqual, RelationLaterOrEqual, RelationLater:
+		case "":
+			return nil, fmt.Errorf("missing version operator")
+		case "<", ">":
+			return nil, fmt.Errorf("deprecated version operator %q", d.Operator)
+		default:
+			return nil, fmt.Errorf("invalid version operator %q", d.Operator)
+		}
+
+		p.skipSpaces()
+
+		d.Version = p.token(func(c byte) bool { return c != ')' && c != ' ' && c != '\t' && c != '\n' })
+		if d.Version == "" {
+			return nil, fmt.Errorf("missing version")
+		} else if _, err := ParseVersion(d.Version); err != nil {
+			return nil, err
+		}
+
+		p.skipSpaces()
+
+		if !p.accept(')') {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+
+		p.skipSpaces()
+	}
+
+	if p.accept('[') {
+		list, err := p.list(']')
+		if err != nil {
+			return nil, err
+		}
+
+		for _, arch := range list {
+			if strings.Trim(strings.TrimPrefix(arch, "!"), archChars) != "" || arch == "!" {
+				return nil, fmt.Errorf("invalid architecture %q", arch)
+			}
+		}
+
+		d.Archs = list
+
+		p.skipSpaces()
+	}
+
+	for p.accept('<') {
+		list, err := p.list('>')
+		if err != nil {
+			return nil, err
+		}
+
+		d.Profiles = append(d.Profiles, list)
+
+		p.skipSpaces()
+	}
+
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected %q", p.input[p.pos:])
+	}
+
+	return d, nil
+}
+
+func (p *relationParser) accept(c byte) bool {
+	if p.pos < len(p.input) && p.input[p.pos] == c {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *relationParser) list(end byte) ([]string, error) {
+	idx := strings.IndexByte(p.input[p.pos:], end)
+	if idx == -1 {
+		return nil, fmt.Errorf("missing closing %q", end)
+	}
+
+	list := strings.Fields(p.input[p.pos : p.pos+idx])
+	if len(list) == 0 {
+		return nil, fmt.Errorf("empty list")
+	}
+
+	p.pos += idx + 1
+
+	return list, nil
+}
+
+func (p *relationParser) skipSpaces() {
+	for p.pos < len(p.input) && strings.IndexByte(" \t\n", p.input[p.pos]) != -1 {
+		p.pos++
+	}
+}
+
+func (p *relationParser) token(fn func(byte) bool) string {
+	start := p.pos
+	for p.pos < len(p.input) && fn(p.input[p.pos]) {
+		p.pos++
+	}
+
+	return p.input[start:p.pos]
+}
+
+const archChars = "abcdefghijklmnopqrstuvwxyz0123456789-"
+
+func isArchChar(c byte) bool {
+	return strings.IndexByte(archChars, c) != -1
+}
+
+func isPackageNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.'
+}
+
+func validPackageName(name string) bool {
+	return len(name) >= 2 && (name[0] >= 'a' && name[0] <= 'z' || name[0] >= '0' && name[0] <= '9')
+}
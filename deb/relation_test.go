@@ -0,0 +1,105 @@
+package deb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRelations(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected []Relation
+	}{
+		{
+			input:    "foo",
+			expected: []Relation{{{Name: "foo"}}},
+		},
+		{
+			input: "libc6 (>= 2.17), foo | bar (<< 1:2.0~rc1)",
+			expected: []Relation{
+				{{Name: "libc6", Operator: RelationLaterOrEqual, Version: "2.17"}},
+				{{Name: "foo"}, {Name: "bar", Operator: RelationEarlier, Version: "1:2.0~rc1"}},
+			},
+		},
+		{
+			input:    "python3:any (>=3.5)",
+			expected: []Relation{{{Name: "python3", ArchQualifier: "any", Operator: RelationLaterOrEqual, Version: "3.5"}}},
+		},
+		{
+			input: "foo [amd64 !i386] <!nocheck> <cross>",
+			expected: []Relation{{{Name: "foo", Archs: []string{"amd64", "!i386"},
+				Profiles: [][]string{{"!nocheck"}, {"cross"}}}}},
+		},
+		{
+			input:    "g++-9 (= 9.3.0-10)",
+			expected: []Relation{{{Name: "g++-9", Operator: RelationEqual, Version: "9.3.0-10"}}},
+		},
+	} {
+		relations, err := ParseRelations(test.input)
+		assert.Nil(t, err, test.input)
+		assert.Equal(t, test.expected, relations, test.input)
+	}
+}
+
+func TestParseRelationsInvalid(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"foo,",
+		"foo |",
+		"Foo",
+		"f",
+		"foo:",
+		"libc6 (>= 2.17",
+		"libc6 (> 2.17)",
+		"libc6 (2.17)",
+		"libc6 (>=)",
+		"libc6 (>= v2.17)",
+		"foo [amd64",
+		"foo []",
+		"foo [AMD64]",
+		"foo <nocheck",
+		"foo bar",
+	} {
+		_, err := ParseRelations(input)
+		assert.True(t, errors.Is(err, ErrInvalidRelation), input)
+	}
+}
+
+func TestParseFieldRelations(t *testing.T) {
+	for _, test := range []struct {
+		field string
+		input string
+		valid bool
+	}{
+		{"Depends", "foo (>= 1.0) [amd64] <!nocheck>", true},
+		{"Provides", "foo, bar (= 1.0)", true},
+		{"Provides", "foo (>= 1.0)", false},
+		{"Provides", "foo [amd64]", false},
+		{"Provides", "foo <!nocheck>", false},
+		{"Built-Using", "foo (= 1.0)", true},
+		{"Built-Using", "foo", false},
+		{"built-using", "foo (>= 1.0)", false},
+		{"Depends", "foo (< 1.0)", false},
+	} {
+		_, err := ParseFieldRelations(test.field, test.input)
+		if test.valid {
+			assert.Nil(t, err, "%s: %s", test.field, test.input)
+		} else {
+			assert.True(t, errors.Is(err, ErrInvalidRelation), "%s: %s", test.field, test.input)
+		}
+	}
+}
+
+func TestRelationString(t *testing.T) {
+	for _, input := range []string{
+		"foo",
+		"python3:any (>= 3.5) | python (<< 3)",
+		"foo [amd64 !i386] <!nocheck> <cross>",
+	} {
+		rel, err := ParseRelation(input)
+		assert.Nil(t, err)
+		assert.Equal(t, input, rel.String())
+	}
+}
@@ -0,0 +1,75 @@
+package deb
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+// Signer is a Debian package signer.
+type Signer interface {
+	// Sign writes a detached signature of the data read from r to w.
+	Sign(w io.Writer, r io.Reader) error
+}
+
+// OpenPGPSigner is an OpenPGP package signer, producing armored detached signatures.
+type OpenPGPSigner struct {
+	Entity *openpgp.Entity
+}
+
+// NewOpenPGPSigner creates a new OpenPGP package signer instance given a private key file path.
+//
+// Both armored and binary keys are supported. If the private key is encrypted, it will be decrypted using the given
+// passphrase.
+func NewOpenPGPSigner(path string, passphrase []byte) (*OpenPGPSigner, error) {
+	var list openpgp.EntityList
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN ")) {
+		list, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	} else {
+		list, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read key: %w", err)
+	}
+
+	// Only consider first entity holding a private key
+	for _, entity := range list {
+		if entity.PrivateKey == nil {
+			continue
+		}
+
+		if entity.PrivateKey.Encrypted {
+			err = entity.PrivateKey.Decrypt(passphrase)
+			if err != nil {
+				return nil, fmt.Errorf("cannot decrypt key: %w", err)
+			}
+		}
+
+		for _, subkey := range entity.Subkeys {
+			if subkey.PrivateKey != nil && subkey.PrivateKey.Encrypted {
+				err = subkey.PrivateKey.Decrypt(passphrase)
+				if err != nil {
+					return nil, fmt.Errorf("cannot decrypt subkey: %w", err)
+				}
+			}
+		}
+
+		return &OpenPGPSigner{Entity: entity}, nil
+	}
+
+	return nil, ErrMissingPrivateKey
+}
+
+// Sign satisfies the Signer interface.
+func (s *OpenPGPSigner) Sign(w io.Writer, r io.Reader) error {
+	return openpgp.ArmoredDetachSign(w, s.Entity, r, nil)
+}
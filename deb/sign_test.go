@@ -0,0 +1,90 @@
+package deb
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blakesmith/ar"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/openpgp"
+)
+
+func TestPackageSign(t *testing.T) {
+	entity, err := openpgp.NewEntity("Foo Bar", "", "foo@example.org", nil)
+	assert.Nil(t, err)
+
+	dir, err := ioutil.TempDir("", "mkdeb-deb")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	key := bytes.NewBuffer(nil)
+	assert.Nil(t, entity.SerializePrivate(key, nil))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "key.gpg"), key.Bytes(), 0600))
+
+	signer, err := NewOpenPGPSigner(filepath.Join(dir, "key.gpg"), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, entity.PrimaryKey.KeyId, signer.Entity.PrimaryKey.KeyId)
+
+	p, err := NewPackage("foo", "all", "1.2.3", 0, 1)
+	assert.Nil(t, err)
+	p.Signer = signer
+
+	err = p.AddFile("/usr/bin/foo", strings.NewReader("foo\n"), newFileInfo("foo", 4, 0755, time.Now(), false))
+	assert.Nil(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, p.Write(buf))
+
+	// Read back package members and check for origin signature
+	names := []string{}
+	members := map[string][]byte{}
+
+	r := ar.NewReader(buf)
+	for {
+		h, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+
+		data, err := ioutil.ReadAll(r)
+		assert.Nil(t, err)
+
+		names = append(names, h.Name)
+		members[h.Name] = data
+	}
+	assert.Equal(t, []string{"debian-binary", "control.tar.gz", "data.tar.xz", "_gpgorigin"}, names)
+
+	signed := io.MultiReader(
+		bytes.NewReader(members["debian-binary"]),
+		bytes.NewReader(members["control.tar.gz"]),
+		bytes.NewReader(members["data.tar.xz"]),
+	)
+
+	signedBy, err := openpgp.CheckArmoredDetachedSignature(openpgp.EntityList{entity}, signed,
+		bytes.NewReader(members["_gpgorigin"]))
+	assert.Nil(t, err)
+	assert.Equal(t, entity.PrimaryKey.KeyId, signedBy.PrimaryKey.KeyId)
+}
+
+func TestOpenPGPSignerMissingPrivateKey(t *testing.T) {
+	entity, err := openpgp.NewEntity("Foo Bar", "", "foo@example.org", nil)
+	assert.Nil(t, err)
+
+	dir, err := ioutil.TempDir("", "mkdeb-deb")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	key := bytes.NewBuffer(nil)
+	assert.Nil(t, entity.Serialize(key))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "key.gpg"), key.Bytes(), 0600))
+
+	_, err = NewOpenPGPSigner(filepath.Join(dir, "key.gpg"), nil)
+	assert.Equal(t, ErrMissingPrivateKey, err)
+}
@@ -1,6 +1,10 @@
 package deb
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 // Version is a Debian package version.
 type Version struct {
@@ -29,3 +33,141 @@ func (v *Version) String() string {
 
 	return s
 }
+
+// ParseVersion parses a Debian package version string (see deb-version(7) for details).
+func ParseVersion(s string) (*Version, error) {
+	v := &Version{}
+
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty version", ErrInvalidVersion)
+	} else if strings.ContainsAny(s, " \t\n") {
+		return nil, fmt.Errorf("%w: %q contains spaces", ErrInvalidVersion, s)
+	}
+
+	upstream := s
+
+	if idx := strings.Index(upstream, ":"); idx != -1 {
+		epoch, err := strconv.ParseUint(upstream[:idx], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q has an invalid epoch", ErrInvalidVersion, s)
+		}
+
+		v.Epoch = uint(epoch)
+		upstream = upstream[idx+1:]
+	}
+
+	if idx := strings.LastIndex(upstream, "-"); idx != -1 {
+		v.Revision = upstream[idx+1:]
+		if v.Revision == "" {
+			return nil, fmt.Errorf("%w: %q has an empty revision", ErrInvalidVersion, s)
+		} else if strings.Trim(v.Revision, versionChars) != "" {
+			return nil, fmt.Errorf("%w: %q has an invalid revision", ErrInvalidVersion, s)
+		}
+
+		upstream = upstream[:idx]
+	}
+
+	if upstream == "" {
+		return nil, fmt.Errorf("%w: %q has an empty upstream version", ErrInvalidVersion, s)
+	} else if !isDigit(upstream[0]) {
+		return nil, fmt.Errorf("%w: %q must start with a digit", ErrInvalidVersion, s)
+	} else if strings.Trim(upstream, versionChars+"-:") != "" {
+		return nil, fmt.Errorf("%w: %q has an invalid upstream version", ErrInvalidVersion, s)
+	}
+
+	v.Upstream = upstream
+
+	return v, nil
+}
+
+// Compare compares two Debian package versions following the dpkg algorithm.
+//
+// It returns 0 if a == b, -1 if a < b and +1 if a > b.
+func Compare(a, b *Version) int {
+	if a.Epoch > b.Epoch {
+		return 1
+	} else if a.Epoch < b.Epoch {
+		return -1
+	}
+
+	if cmp := compareString(a.Upstream, b.Upstream); cmp != 0 {
+		return cmp
+	}
+
+	return compareString(a.Revision, b.Revision)
+}
+
+// compareString compares version parts by alternating non-digit and digit segments.
+func compareString(a, b string) int {
+	for a != "" || b != "" {
+		// Compare non-digit prefixes character by character
+		for a != "" && !isDigit(a[0]) || b != "" && !isDigit(b[0]) {
+			ac, bc := versionOrder(a), versionOrder(b)
+			if ac != bc {
+				return sign(ac - bc)
+			}
+
+			if a != "" {
+				a = a[1:]
+			}
+			if b != "" {
+				b = b[1:]
+			}
+		}
+
+		// Skip leading zeros then compare digit segments numerically
+		a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+
+		var cmp int
+		for a != "" && isDigit(a[0]) && b != "" && isDigit(b[0]) {
+			if cmp == 0 {
+				cmp = sign(int(a[0]) - int(b[0]))
+			}
+
+			a, b = a[1:], b[1:]
+		}
+
+		if a != "" && isDigit(a[0]) {
+			return 1
+		} else if b != "" && isDigit(b[0]) {
+			return -1
+		} else if cmp != 0 {
+			return cmp
+		}
+	}
+
+	return 0
+}
+
+const versionChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789.+~"
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func sign(v int) int {
+	if v > 0 {
+		return 1
+	} else if v < 0 {
+		return -1
+	}
+
+	return 0
+}
+
+// versionOrder returns the sorting weight of the first character of a version part: tildes sort before anything,
+// even the end of the part, and letters sort before non-letters.
+func versionOrder(s string) int {
+	switch {
+	case s == "" || isDigit(s[0]):
+		return 0
+
+	case s[0] == '~':
+		return -1
+
+	case s[0] >= 'A' && s[0] <= 'Z' || s[0] >= 'a' && s[0] <= 'z':
+		return int(s[0])
+	}
+
+	return int(s[0]) + 256
+}
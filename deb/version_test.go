@@ -1,6 +1,7 @@
 package deb
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -21,3 +22,111 @@ func TestVersionRevision(t *testing.T) {
 func TestVersionEpochRevision(t *testing.T) {
 	assert.Equal(t, "1:1.2.3-1", NewVersion(1, "1.2.3", "1").String())
 }
+
+func TestParseVersion(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected *Version
+	}{
+		{"1.2.3", &Version{0, "1.2.3", ""}},
+		{"1.2.3-1", &Version{0, "1.2.3", "1"}},
+		{"1:1.2.3", &Version{1, "1.2.3", ""}},
+		{"1:1.2.3-1~mkdeb1", &Version{1, "1.2.3", "1~mkdeb1"}},
+		{"0:1.2.3", &Version{0, "1.2.3", ""}},
+		{"1.2.3-rc1-2", &Version{0, "1.2.3-rc1", "2"}},
+		{"2:1.2:3-1", &Version{2, "1.2:3", "1"}},
+		{"1.2.3+dfsg~rc1", &Version{0, "1.2.3+dfsg~rc1", ""}},
+	} {
+		v, err := ParseVersion(test.input)
+		assert.Nil(t, err, test.input)
+		assert.Equal(t, test.expected, v, test.input)
+	}
+}
+
+func TestParseVersionInvalid(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"1.2 3",
+		":1.2.3",
+		"a:1.2.3",
+		"-1:1.2.3",
+		"1:",
+		"1.2.3-",
+		"-1",
+		"v1.2.3",
+		"1.2.3_1",
+		"1.2.3-1_1",
+		"1.2.3-1:1",
+	} {
+		_, err := ParseVersion(input)
+		assert.True(t, errors.Is(err, ErrInvalidVersion), input)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	for _, test := range []struct {
+		a, b     string
+		expected int
+	}{
+		// Equality
+		{"1.0", "1.0", 0},
+		{"0:1.0", "1.0", 0},
+		{"1.0-0", "1.0", 0},
+		{"1.001", "1.1", 0},
+		{"1.0-1", "1.0-1", 0},
+		{"1:1.0-1", "1:1.0-1", 0},
+
+		// Epochs
+		{"1:1.0", "2.0", 1},
+		{"2:1.0", "1:9.9", 1},
+		{"1:0.1", "0:9.9", 1},
+
+		// Numeric segments
+		{"1.0", "2.0", -1},
+		{"2.0", "1.0", 1},
+		{"1.10", "1.9", 1},
+		{"1.0.1", "1.0", 1},
+		{"1.0", "1.0.0", -1},
+		{"10", "9", 1},
+		{"1.2.3", "1.2.10", -1},
+		{"20200101", "20191231", 1},
+
+		// Non-digit segments
+		{"1.0a", "1.0b", -1},
+		{"1.0a", "1.0", 1},
+		{"1.0a", "1.0+", -1},
+		{"1.0+dfsg", "1.0", 1},
+		{"1.0+a", "1.0.a", -1},
+		{"1.0A", "1.0a", -1},
+		{"1.0.a", "1.0.0", 1},
+
+		// Tildes
+		{"1.0~rc1", "1.0", -1},
+		{"1.0~rc1", "1.0~rc2", -1},
+		{"1.0~rc1~git", "1.0~rc1", -1},
+		{"1.0~~", "1.0~~a", -1},
+		{"1.0~~a", "1.0~", -1},
+		{"1.0~", "1.0", -1},
+		{"1.0", "1.0a", -1},
+		{"1.0~beta", "1.0~alpha", 1},
+
+		// Revisions
+		{"1.0-1", "1.0-2", -1},
+		{"1.0-1", "1.0", 1},
+		{"1.0-1ubuntu1", "1.0-1", 1},
+		{"1.0-1~bpo1", "1.0-1", -1},
+		{"1.2.3-1~mkdeb1", "1.2.3-1~mkdeb2", -1},
+		{"1.2.3-1~mkdeb10", "1.2.3-1~mkdeb9", 1},
+		{"1.0-1.1", "1.0-1", 1},
+		{"1.0-rc1-1", "1.0-1", 1},
+	} {
+		a, err := ParseVersion(test.a)
+		assert.Nil(t, err, test.a)
+
+		b, err := ParseVersion(test.b)
+		assert.Nil(t, err, test.b)
+
+		assert.Equal(t, test.expected, Compare(a, b), "%s <=> %s", test.a, test.b)
+		assert.Equal(t, -test.expected, Compare(b, a), "%s <=> %s", test.b, test.a)
+	}
+}
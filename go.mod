@@ -13,6 +13,7 @@ require (
 	github.com/h2non/filetype v1.1.0
 	github.com/ikawaha/kagome.ipadic v1.1.2 // indirect
 	github.com/jmhodges/levigo v1.0.0 // indirect
+	github.com/klauspost/compress v1.11.13
 	github.com/mattn/go-colorable v0.1.8 // indirect
 	github.com/mgutz/ansi v0.0.0-20200706080929-d51e80ef957d
 	github.com/mitchellh/go-homedir v1.1.0
@@ -23,6 +24,7 @@ require (
 	github.com/ulikunitz/xz v0.5.8
 	github.com/urfave/cli v1.22.5
 	github.com/urfave/cli/v2 v2.3.0
+	golang.org/x/crypto v0.0.0-20200302210943-78000ba7a073
 	golang.org/x/text v0.3.4
 	gopkg.in/yaml.v3 v3.0.0-20200615113413-eeeca48fe776
 )
@@ -0,0 +1,90 @@
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// ConfigFile is the name of the linting configuration file located at recipes repositories root.
+const ConfigFile = ".mkdeb-lint.yaml"
+
+// Config is a linting configuration.
+type Config struct {
+	// Disable are the rules tags to disable.
+	Disable []string `yaml:"disable"`
+
+	// Promote are the warning rules tags to promote to errors.
+	Promote []string `yaml:"promote"`
+
+	// Strict promotes all warnings to errors.
+	Strict bool `yaml:"strict"`
+}
+
+// LoadConfig loads the linting configuration of a recipes repository given its root path. An empty configuration
+// is returned if the repository has no configuration file.
+func LoadConfig(path string) (*Config, error) {
+	cfg := &Config{}
+
+	data, err := ioutil.ReadFile(filepath.Join(path, ConfigFile))
+	if os.IsNotExist(err) {
+		return cfg, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	err = dec.Decode(cfg)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("cannot decode configuration: %w", err)
+	}
+
+	for _, tags := range [][]string{cfg.Disable, cfg.Promote} {
+		for _, tag := range tags {
+			if _, ok := rules[tag]; !ok {
+				return nil, fmt.Errorf("%w: %q", ErrUnknownRule, tag)
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+// apply applies the configuration to linting problems, filtering out disabled and ignored rules and promoting
+// warnings to errors if requested.
+func (c *Config) apply(problems []*Problem, ignore []string) []*Problem {
+	var result []*Problem
+
+	disabled := map[string]bool{}
+	for _, tags := range [][]string{c.Disable, ignore} {
+		for _, tag := range tags {
+			disabled[tag] = true
+		}
+	}
+
+	promoted := map[string]bool{}
+	for _, tag := range c.Promote {
+		promoted[tag] = true
+	}
+
+	for _, p := range problems {
+		if disabled[p.Tag] {
+			continue
+		}
+
+		if p.Level == LevelWarning && (c.Strict || promoted[p.Tag]) {
+			p.Level = LevelError
+		}
+
+		result = append(result, p)
+	}
+
+	return result
+}
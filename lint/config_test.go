@@ -0,0 +1,43 @@
+package lint
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfig(t *testing.T) {
+	for _, test := range []struct {
+		path     string
+		expected *Config
+		err      error
+	}{
+		{
+			path: "testdata/config/valid",
+			expected: &Config{
+				Disable: []string{"homepage-empty"},
+				Promote: []string{"control-description-wrap"},
+			},
+		},
+		{
+			path:     "testdata/config/missing",
+			expected: &Config{},
+		},
+		{
+			path: "testdata/config/unknown-rule",
+			err:  ErrUnknownRule,
+		},
+	} {
+		cfg, err := LoadConfig(test.path)
+		if test.err != nil {
+			assert.True(t, errors.Is(err, test.err))
+		} else {
+			assert.Nil(t, err)
+		}
+		assert.Equal(t, test.expected, cfg)
+	}
+
+	_, err := LoadConfig("testdata/config/unknown-field")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,10 @@
+package lint
+
+import "errors"
+
+var (
+	// ErrUnknownRule is an unknown linting rule error.
+	ErrUnknownRule = errors.New("unknown rule")
+	// ErrUnsupportedFormat is an unsupported report format error.
+	ErrUnsupportedFormat = errors.New("unsupported report format")
+)
@@ -0,0 +1,398 @@
+package lint
+
+import (
+	"bytes"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mitchellh/go-wordwrap"
+	yaml "gopkg.in/yaml.v3"
+
+	"mkdeb.sh/deb"
+	"mkdeb.sh/recipe"
+)
+
+// Fix is a linting problem fix applied to a recipe file.
+type Fix struct {
+	Tag      string
+	Position *Position
+	Old      string
+	New      string
+}
+
+type fixer struct {
+	data  []byte
+	lines [][]byte
+	fixes []*Fix
+	edits []*edit
+}
+
+// edit is a replacement of a recipe file content bytes range.
+type edit struct {
+	start int
+	end   int
+	text  string
+}
+
+// ApplyFixes fixes the recipe file content problems having a mechanical fix, returning the fixed content along with
+// the applied fixes.
+//
+// Fields to fix are located using the YAML document nodes, their fixed values being spliced in the original content
+// at the nodes positions so that formatting, comments and keys order are preserved.
+func ApplyFixes(data []byte) ([]byte, []*Fix, error) {
+	var doc yaml.Node
+
+	err := yaml.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, nil, err
+	} else if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return data, nil, nil
+	}
+
+	f := &fixer{
+		data:  data,
+		lines: bytes.SplitAfter(data, []byte("\n")),
+	}
+
+	root := doc.Content[0]
+
+	if key, value := lookupNode(root, "description"); value != nil {
+		f.fixDescription(key, value)
+	}
+
+	if _, control := lookupNode(root, "control"); control != nil {
+		if key, value := lookupNode(control, "description"); value != nil {
+			f.fixControlDescription(key, value)
+		}
+	}
+
+	installs := []*yaml.Node{}
+	if _, install := lookupNode(root, "install"); install != nil {
+		installs = append(installs, install)
+	}
+
+	if _, overrides := lookupNode(root, "arch-overrides"); overrides != nil && overrides.Kind == yaml.MappingNode {
+		for i := 1; i < len(overrides.Content); i += 2 {
+			if _, install := lookupNode(overrides.Content[i], "install"); install != nil {
+				installs = append(installs, install)
+			}
+		}
+	}
+
+	for _, install := range installs {
+		for _, subkey := range []string{"recipe", "upstream"} {
+			if _, m := lookupNode(install, subkey); m != nil && m.Kind == yaml.MappingNode {
+				for i := 0; i < len(m.Content); i += 2 {
+					f.fixInstallDestination(m.Content[i])
+				}
+			}
+		}
+	}
+
+	if _, links := lookupNode(root, "links"); links != nil && links.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(links.Content); i += 2 {
+			f.fixLink(links.Content[i], links.Content[i+1])
+		}
+	}
+
+	if len(f.edits) == 0 {
+		return data, nil, nil
+	}
+
+	// Apply edits starting from the end of the content, keeping the preceding offsets valid
+	sort.Slice(f.edits, func(i, j int) bool {
+		return f.edits[i].start > f.edits[j].start
+	})
+
+	result := append([]byte{}, data...)
+	for _, e := range f.edits {
+		result = append(result[:e.start], append([]byte(e.text), result[e.end:]...)...)
+	}
+
+	sort.SliceStable(f.fixes, func(i, j int) bool {
+		a, b := f.fixes[i].Position, f.fixes[j].Position
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+
+	return result, f.fixes, nil
+}
+
+func (f *fixer) fixDescription(key, node *yaml.Node) {
+	v := node.Value
+	if v == "" {
+		return
+	}
+
+	if r, size := utf8.DecodeRuneInString(v); unicode.IsLower(r) {
+		fixed := string(unicode.ToUpper(r)) + v[size:]
+		if f.replace(key, node, fixed) {
+			f.fix("description-missing-uppercase", node, v, fixed)
+			v = fixed
+		}
+	}
+
+	if strings.HasSuffix(v, ".") {
+		fixed := strings.TrimRight(v, ".")
+		if f.replace(key, node, fixed) {
+			f.fix("description-extra-dot", node, v, fixed)
+		}
+	}
+}
+
+func (f *fixer) fixControlDescription(key, node *yaml.Node) {
+	lines := strings.Split(node.Value, "\n")
+
+	wrapped := false
+	for idx, line := range lines {
+		// Leave indented lines as is, as they're meant to be displayed verbatim
+		if len(line) > deb.ControlDescriptionWrap && !strings.HasPrefix(line, " ") {
+			lines[idx] = wordwrap.WrapString(line, deb.ControlDescriptionWrap)
+			wrapped = true
+		}
+	}
+
+	fixed := strings.Join(lines, "\n")
+	if wrapped && fixed != node.Value && f.replace(key, node, fixed) {
+		f.fix("control-description-wrap", node, node.Value, fixed)
+	}
+}
+
+func (f *fixer) fixInstallDestination(node *yaml.Node) {
+	if fixed, ok := absPath(node.Value); ok && f.replace(nil, node, fixed) {
+		f.fix("install-destination-relative", node, node.Value, fixed)
+	}
+}
+
+func (f *fixer) fixLink(dstNode, srcNode *yaml.Node) {
+	dst := dstNode.Value
+	if fixed, ok := absPath(dst); ok && f.replace(nil, dstNode, fixed) {
+		f.fix("links-destination-relative", dstNode, dst, fixed)
+		dst = fixed
+	}
+
+	// Resolve relative sources against the link directory, as symbolic links targets would be
+	if _, ok := absPath(srcNode.Value); ok && filepath.IsAbs(dst) {
+		fixed := path.Join(path.Dir(dst), srcNode.Value)
+		if f.replace(nil, srcNode, fixed) {
+			f.fix("links-source-relative", srcNode, srcNode.Value, fixed)
+		}
+	}
+}
+
+func (f *fixer) fix(tag string, node *yaml.Node, old, fixed string) {
+	f.fixes = append(f.fixes, &Fix{
+		Tag:      tag,
+		Position: &Position{Line: node.Line, Column: node.Column},
+		Old:      old,
+		New:      fixed,
+	})
+}
+
+// replace registers the replacement of a scalar node value, returning false if the node can't be safely replaced.
+// The mapping key node is required to locate block scalars ends.
+func (f *fixer) replace(key, node *yaml.Node, value string) bool {
+	if node.Kind != yaml.ScalarNode || node.Line < 1 || node.Line > len(f.lines) {
+		return false
+	}
+
+	start, ok := f.offset(node.Line, node.Column)
+	if !ok {
+		return false
+	}
+
+	var (
+		end  int
+		text string
+	)
+
+	if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 || strings.Contains(value, "\n") {
+		if key == nil {
+			return false
+		}
+
+		end, ok = f.blockEnd(key, node)
+		if !ok {
+			return false
+		}
+
+		text, ok = literalBlock(value, f.blockIndent(key, node))
+		if !ok {
+			return false
+		}
+	} else {
+		old, err := encodeScalar(node.Value, node.Style)
+		if err != nil || !bytes.HasPrefix(f.data[start:], []byte(old)) {
+			return false
+		}
+
+		end = start + len(old)
+
+		text, err = encodeScalar(value, node.Style)
+		if err != nil {
+			return false
+		}
+	}
+
+	// Replace any pending edit of the same node, as multiple fixes might apply to a single value
+	for idx, e := range f.edits {
+		if e.start == start {
+			f.edits = append(f.edits[:idx], f.edits[idx+1:]...)
+			break
+		}
+	}
+
+	f.edits = append(f.edits, &edit{start, end, text})
+
+	return true
+}
+
+// offset returns the content byte offset of a node position, columns being counted in characters.
+func (f *fixer) offset(line, column int) (int, bool) {
+	offset := 0
+	for _, l := range f.lines[:line-1] {
+		offset += len(l)
+	}
+
+	cur := f.lines[line-1]
+	for i := 1; i < column; i++ {
+		if len(cur) == 0 {
+			return 0, false
+		}
+
+		_, size := utf8.DecodeRune(cur)
+		cur = cur[size:]
+		offset += size
+	}
+
+	return offset, true
+}
+
+// blockEnd returns the content byte offset at which a scalar node ends, including its block content lines if any.
+func (f *fixer) blockEnd(key, node *yaml.Node) (int, bool) {
+	start, _ := f.offset(node.Line, node.Column)
+	header := f.lines[node.Line-1]
+
+	// Scalars starting on the key line and not being blocks can only be replaced if on a single line
+	if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+		old, err := encodeScalar(node.Value, node.Style)
+		if err != nil || !bytes.HasPrefix(f.data[start:], []byte(old)) {
+			return 0, false
+		}
+
+		// Converting to a block scalar requires nothing else to follow on the line
+		end := start + len(old)
+		rest := f.data[end:]
+		if idx := bytes.IndexByte(rest, '\n'); idx != -1 {
+			rest = rest[:idx]
+		}
+
+		if len(bytes.TrimSpace(rest)) > 0 {
+			return 0, false
+		}
+
+		return end, true
+	}
+
+	// Preserve comments following the block header
+	lineStart, _ := f.offset(node.Line, 1)
+	if bytes.Contains(header[start-lineStart:], []byte("#")) {
+		return 0, false
+	}
+
+	end := lineStart + len(bytes.TrimRight(header, "\r\n"))
+	offset := lineStart + len(header)
+
+	for _, line := range f.lines[node.Line:] {
+		trimmed := strings.TrimRight(string(line), "\r\n")
+		if strings.TrimSpace(trimmed) != "" {
+			if indent(trimmed) <= key.Column-1 {
+				break
+			}
+
+			end = offset + len(trimmed)
+		}
+
+		offset += len(line)
+	}
+
+	return end, true
+}
+
+// blockIndent returns the indentation of a block scalar content lines.
+func (f *fixer) blockIndent(key, node *yaml.Node) int {
+	if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		for _, line := range f.lines[node.Line:] {
+			if s := strings.TrimRight(string(line), "\r\n"); strings.TrimSpace(s) != "" {
+				if n := indent(s); n > key.Column-1 {
+					return n
+				}
+				break
+			}
+		}
+	}
+
+	return key.Column - 1 + 2
+}
+
+// literalBlock returns the literal block scalar representation of a value, header included.
+func literalBlock(value string, indentation int) (string, bool) {
+	header := "|-"
+	if strings.HasSuffix(value, "\n\n") || strings.HasPrefix(value, " ") {
+		return "", false
+	} else if strings.HasSuffix(value, "\n") {
+		header = "|"
+		value = value[:len(value)-1]
+	}
+
+	lines := strings.Split(value, "\n")
+	for idx, line := range lines {
+		if line != "" {
+			lines[idx] = strings.Repeat(" ", indentation) + line
+		}
+	}
+
+	return header + "\n" + strings.Join(lines, "\n"), true
+}
+
+// encodeScalar returns the YAML representation of a single-line scalar value given its style.
+func encodeScalar(value string, style yaml.Style) (string, error) {
+	data, err := yaml.Marshal(&yaml.Node{Kind: yaml.ScalarNode, Style: style, Value: value})
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// lookupNode returns the key and value nodes of a mapping node entry.
+func lookupNode(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+
+	return nil, nil
+}
+
+// absPath returns the absolute version of a relative path template, if it renders to a relative path.
+func absPath(v string) (string, bool) {
+	rendered, err := recipe.Render(v, templateData)
+	if err != nil || v == "" || filepath.IsAbs(rendered) {
+		return "", false
+	}
+
+	return "/" + v, true
+}
+
+func indent(s string) int {
+	return len(s) - len(strings.TrimLeft(s, " "))
+}
@@ -0,0 +1,72 @@
+package lint
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyFixes(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/fix/recipe.yaml")
+	assert.Nil(t, err)
+
+	expected, err := ioutil.ReadFile("testdata/fix/expected.yaml")
+	assert.Nil(t, err)
+
+	result, fixes, err := ApplyFixes(data)
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), string(result))
+	assert.Equal(t, []*Fix{
+		{"description-missing-uppercase", &Position{"", 5, 14}, "a great description.", "A great description."},
+		{"description-extra-dot", &Position{"", 5, 14}, "A great description.", "A great description"},
+		{"control-description-wrap", &Position{"", 20, 16},
+			"A long package description providing us with information on the upstream software, wrapped too " +
+				"late.\n\nVerbatim lines are left untouched:\n verbatim line being way too long but displayed as is " +
+				"by package managers, thus never being wrapped\n",
+			"A long package description providing us with information on the upstream\nsoftware, wrapped too " +
+				"late.\n\nVerbatim lines are left untouched:\n verbatim line being way too long but displayed as is " +
+				"by package managers, thus never being wrapped\n"},
+		{"install-destination-relative", &Position{"", 29, 5}, "etc/init.d", "/etc/init.d"},
+		{"install-destination-relative", &Position{"", 34, 5}, "usr/bin", "/usr/bin"},
+		{"install-destination-relative", &Position{"", 41, 9}, "usr/{{ .Name }}/bin", "/usr/{{ .Name }}/bin"},
+		{"links-destination-relative", &Position{"", 45, 3}, "usr/bin/bar", "/usr/bin/bar"},
+		{"links-source-relative", &Position{"", 45, 16}, "../lib/foo/bar", "/usr/lib/foo/bar"},
+	}, fixes)
+
+	// Fixed content must not have any fix left to apply
+	result, fixes, err = ApplyFixes(expected)
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), string(result))
+	assert.Nil(t, fixes)
+}
+
+func TestApplyFixesInvalid(t *testing.T) {
+	_, _, err := ApplyFixes([]byte("name: [foo"))
+	assert.NotNil(t, err)
+}
+
+func TestApplyFixesLiteralBlock(t *testing.T) {
+	result, fixes, err := ApplyFixes([]byte(`control:
+  description: A long package description providing us with information on the upstream software.
+  section: admin
+`))
+	assert.Nil(t, err)
+	assert.Len(t, fixes, 1)
+	assert.Equal(t, `control:
+  description: |-
+    A long package description providing us with information on the upstream
+    software.
+  section: admin
+`, string(result))
+
+	// Scalars followed by comments can't be converted to blocks
+	data := []byte(`control:
+  description: A long package description providing us with information on the upstream software. # foo
+`)
+
+	result, fixes, err = ApplyFixes(data)
+	assert.Nil(t, err)
+	assert.Nil(t, fixes)
+	assert.Equal(t, string(data), string(result))
+}
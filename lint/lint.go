@@ -2,12 +2,16 @@ package lint
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/mail"
 	"net/url"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
 	"strings"
-	"text/template"
 
 	"mkdeb.sh/deb"
 	"mkdeb.sh/recipe"
@@ -15,6 +19,31 @@ import (
 
 //go:generate go run internal/generate/main.go -o rules.go
 
+// templateData is the sample data used to check recipe templates.
+var templateData = &recipe.TemplateData{
+	Name:         "name",
+	Version:      "1.0.0",
+	Arch:         "arch",
+	DebArch:      "amd64",
+	UpstreamArch: "arch",
+}
+
+// controlFiles are the supported package control files, mapped to whether they are maintainer scripts.
+var controlFiles = map[string]bool{
+	"config":    true,
+	"postinst":  true,
+	"postrm":    true,
+	"preinst":   true,
+	"prerm":     true,
+	"templates": false,
+	"triggers":  false,
+}
+
+var (
+	setERegexp = regexp.MustCompile(`(?m)^\s*set\s+-[a-zA-Z]*e`)
+	shells     = map[string]bool{"ash": true, "bash": true, "dash": true, "ksh": true, "sh": true, "zsh": true}
+)
+
 // Levels:
 const (
 	_ = iota
@@ -28,36 +57,63 @@ func Info(tag string) *RuleInfo {
 }
 
 // Lint inspects recipe and tries to detect problems or guidelines violations.
-func Lint(rcp *recipe.Recipe) ([]*Problem, bool) {
-	var failed bool
+//
+// Problems are filtered and their levels adjusted given the linting configuration and the recipe-specific ignored
+// rules. Default rules levels apply if cfg is nil.
+func Lint(rcp *recipe.Recipe, cfg *Config) ([]*Problem, bool) {
+	var ignore []string
 
 	l := &linter{}
 	l.lintVersion(rcp.Version)
+	l.lintFields(rcp.FieldErrors)
 	l.lintName(rcp.Name)
 	l.lintDescription(rcp.Description)
 	l.lintMaintainer(rcp.Maintainer)
 	l.lintHomepage(rcp.Homepage)
 	l.lintSource(rcp.Source)
+	if rcp.Source != nil {
+		l.lintSourceSignature(rcp.Source.SignatureURL, rcp.KeyFiles)
+	}
 	l.lintControl(rcp.Control)
+	if rcp.Control != nil {
+		l.lintControlRelations(rcp.Name, rcp.Control)
+	}
 	l.lintInstall(rcp.Install)
 	l.lintDirs(rcp.Dirs)
 	l.lintLinks(rcp.Links)
-
-	for _, p := range l.problems {
-		if p.Level == LevelError {
-			failed = true
-			break
-		}
+	l.lintControlFiles(rcp.ControlFiles)
+	l.lintRecipeFiles(rcp)
+	l.lintArchOverrides(rcp)
+	if rcp.Lint != nil {
+		l.lintIgnore(rcp.Lint.Ignore)
+		ignore = rcp.Lint.Ignore
 	}
+	l.resolve(rcp)
 
-	return l.problems, !failed
+	return l.result(cfg, ignore)
 }
 
 // Problem is a linting problem.
 type Problem struct {
-	Level int
-	Tag   string
-	Args  []interface{}
+	Level    int
+	Tag      string
+	Args     []interface{}
+	Position *Position
+}
+
+// Position is a linting problem position.
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (p *Position) String() string {
+	if p.Line == 0 {
+		return p.File
+	}
+
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
 }
 
 // RuleInfo is linting rule information.
@@ -69,148 +125,308 @@ type RuleInfo struct {
 
 type linter struct {
 	problems []*Problem
+
+	// paths are the recipe fields paths problems have been emitted for, used to resolve their positions
+	paths [][]string
 }
 
-func (l *linter) emit(tag string, args ...interface{}) {
+func (l *linter) emit(path []string, tag string, args ...interface{}) {
 	r, ok := rules[tag]
 	if !ok {
 		panic(fmt.Sprintf("unsupported %q rule", tag))
 	}
 
-	l.problems = append(l.problems, &Problem{r.Level, tag, args})
+	l.problems = append(l.problems, &Problem{r.Level, tag, args, nil})
+	l.paths = append(l.paths, path)
+}
+
+// result applies the linting configuration to the emitted problems, returning them along with whether linting
+// succeeded.
+func (l *linter) result(cfg *Config, ignore []string) ([]*Problem, bool) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	problems := cfg.apply(l.problems, ignore)
+	for _, p := range problems {
+		if p.Level == LevelError {
+			return problems, false
+		}
+	}
+
+	return problems, true
+}
+
+// emitFile emits a problem located in a recipe file other than the recipe definition one.
+func (l *linter) emitFile(file string, line int, tag string, args ...interface{}) {
+	l.emit(nil, tag, args...)
+	l.problems[len(l.problems)-1].Position = &Position{File: file, Line: line}
+}
+
+// merge merges problems emitted by another linter, prefixing their paths and skipping the ones already emitted.
+func (l *linter) merge(sub *linter, prefix []string) {
+	for idx, p := range sub.problems {
+		found := false
+		for _, cur := range l.problems {
+			if reflect.DeepEqual(p, cur) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			l.problems = append(l.problems, p)
+			l.paths = append(l.paths, append(append([]string{}, prefix...), sub.paths[idx]...))
+		}
+	}
+}
+
+// resolve resolves emitted problems positions in the recipe file.
+func (l *linter) resolve(rcp *recipe.Recipe) {
+	if rcp.Path == "" {
+		return
+	}
+
+	file := filepath.Join(rcp.Path, "recipe.yaml")
+
+	for idx, p := range l.problems {
+		if p.Position == nil {
+			line, column := rcp.Position(l.paths[idx]...)
+			if line == 0 {
+				continue
+			}
+
+			p.Position = &Position{Line: line, Column: column}
+		}
+
+		if p.Position.File == "" {
+			p.Position.File = file
+		}
+	}
+}
+
+// at returns a recipe field path given its keys.
+func at(keys ...interface{}) []string {
+	path := make([]string, len(keys))
+	for idx, key := range keys {
+		path[idx] = fmt.Sprint(key)
+	}
+
+	return path
 }
 
 func (l *linter) lintVersion(v int) {
 	if !recipe.VersionSupported(v) {
-		l.emit("version-unsupported", v)
+		l.emit(at("version"), "version-unsupported", v)
+	} else if v < recipe.CurrentVersion {
+		l.emit(at("version"), "version-outdated", v)
+	}
+}
+
+func (l *linter) lintFields(v recipe.FieldErrors) {
+	for _, err := range v {
+		if errors.Is(err, recipe.ErrUnknownField) {
+			l.emit(nil, "field-unknown", err.Field)
+
+			// Unknown fields positions are already known from the recipe schema validation
+			l.problems[len(l.problems)-1].Position = &Position{Line: err.Line, Column: err.Column}
+		}
 	}
 }
 
 func (l *linter) lintName(v string) {
 	if v == "" {
-		l.emit("name-empty")
+		l.emit(at("name"), "name-empty")
 		return
 	}
 
 	lastIdx := len(v) - 1
 	for idx, b := range v {
 		if !((b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') || (b == '-' && idx > 0 && idx < lastIdx)) {
-			l.emit("name-invalid", v)
+			l.emit(at("name"), "name-invalid", v)
 			return
 		}
 	}
 
 	if len(v) > 60 {
-		l.emit("name-too-long", v)
+		l.emit(at("name"), "name-too-long", v)
 	}
 }
 
 func (l *linter) lintDescription(v string) {
 	if v == "" {
-		l.emit("description-empty")
+		l.emit(at("description"), "description-empty")
 		return
 	}
 
 	if string(v[0]) != strings.ToUpper(string(v[0])) {
-		l.emit("description-missing-uppercase", v)
+		l.emit(at("description"), "description-missing-uppercase", v)
 	}
 
 	if strings.HasSuffix(v, ".") {
-		l.emit("description-extra-dot", v)
+		l.emit(at("description"), "description-extra-dot", v)
 	}
 
 	if len(v) > 120 {
-		l.emit("description-too-long", v)
+		l.emit(at("description"), "description-too-long", v)
 	}
 }
 
 func (l *linter) lintMaintainer(v string) {
 	if v == "" {
-		l.emit("maintainer-empty")
+		l.emit(at("maintainer"), "maintainer-empty")
 		return
 	}
 
 	_, err := mail.ParseAddress(v)
 	if err != nil {
-		l.emit("maintainer-invalid", v)
+		l.emit(at("maintainer"), "maintainer-invalid", v)
 	}
 }
 
 func (l *linter) lintHomepage(v string) {
 	if v == "" {
-		l.emit("homepage-empty")
+		l.emit(at("homepage"), "homepage-empty")
 		return
 	}
 
 	url, err := url.Parse(v)
 	if err != nil || url.Scheme == "" {
-		l.emit("homepage-invalid", v)
+		l.emit(at("homepage"), "homepage-invalid", v)
 	}
 }
 
 func (l *linter) lintSource(v *recipe.Source) {
 	if v == nil {
-		l.emit("source-empty")
+		l.emit(at("source"), "source-empty")
 		return
 	}
 
 	l.lintSourceURL(v.URL)
 	l.lintSourceType(v.Type)
 	l.lintSourceStrip(v.Strip)
+	l.lintSourceChecksums(v.Checksums, v.ChecksumURL)
+	if v.Latest != nil {
+		l.lintSourceLatest(v.Latest)
+	}
 }
 
 func (l *linter) lintSourceURL(v string) {
 	if v == "" {
-		l.emit("source-url-empty")
+		l.emit(at("source", "url"), "source-url-empty")
 		return
 	}
 
-	tmpl, err := template.New("").Parse(v)
-	if err != nil {
-		l.emit("source-url-invalid", v)
-		return
+	if !validURLTemplate(v) {
+		l.emit(at("source", "url"), "source-url-invalid", v)
 	}
+}
 
-	buf := bytes.NewBuffer(nil)
+func (l *linter) lintSourceType(v string) {
+	if v != "" && v != "archive" && v != "file" {
+		l.emit(at("source", "type"), "source-type-invalid", v)
+	}
+}
 
-	err = tmpl.Execute(buf, struct{ Version, Arch string }{"version", "arch"})
-	if err != nil {
-		l.emit("source-url-invalid", v)
+func (l *linter) lintSourceStrip(v int) {
+	if v < 0 {
+		l.emit(at("source", "strip"), "source-strip-invalid", v)
+	}
+}
+
+func (l *linter) lintSourceChecksums(v map[string]map[string]string, checksumURL string) {
+	if len(v) == 0 && checksumURL == "" {
+		l.emit(at("source", "checksums"), "source-checksum-empty")
 		return
 	}
 
-	url, err := url.Parse(buf.String())
-	if err != nil || url.Scheme == "" {
-		l.emit("source-url-invalid", v)
+	versions := make([]string, 0, len(v))
+	for version := range v {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+
+	for _, version := range versions {
+		archs := make([]string, 0, len(v[version]))
+		for arch := range v[version] {
+			archs = append(archs, arch)
+		}
+		sort.Strings(archs)
+
+		for _, arch := range archs {
+			_, err := recipe.ParseChecksum(v[version][arch])
+			if err != nil {
+				l.emit(at("source", "checksums", version, arch), "source-checksum-invalid", version, arch,
+					v[version][arch])
+			}
+		}
+	}
+
+	if checksumURL != "" && !validURLTemplate(checksumURL) {
+		l.emit(at("source", "checksum-url"), "source-checksum-url-invalid", checksumURL)
 	}
 }
 
-func (l *linter) lintSourceType(v string) {
-	if v != "" && v != "archive" && v != "file" {
-		l.emit("source-type-invalid", v)
+func (l *linter) lintSourceLatest(v *recipe.Latest) {
+	switch v.Type {
+	case "github":
+		parts := strings.Split(v.Repository, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			l.emit(at("source", "latest", "repository"), "source-latest-repository-invalid", v.Repository)
+		}
+
+	case "html", "json":
+		url, err := url.Parse(v.URL)
+		if err != nil || url.Scheme == "" {
+			l.emit(at("source", "latest", "url"), "source-latest-url-invalid", v.URL)
+		}
+
+		if v.Type == "html" && v.Pattern == "" {
+			l.emit(at("source", "latest", "pattern"), "source-latest-pattern-empty")
+		}
+
+	default:
+		l.emit(at("source", "latest", "type"), "source-latest-type-invalid", v.Type)
+	}
+
+	if v.Pattern != "" {
+		re, err := regexp.Compile(v.Pattern)
+		if err != nil || re.NumSubexp() < 1 {
+			l.emit(at("source", "latest", "pattern"), "source-latest-pattern-invalid", v.Pattern)
+		}
 	}
 }
 
-func (l *linter) lintSourceStrip(v int) {
-	if v < 0 {
-		l.emit("source-strip-invalid", v)
+func (l *linter) lintSourceSignature(v string, keys []recipe.File) {
+	if v == "" {
+		return
+	}
+
+	if !validURLTemplate(v) {
+		l.emit(at("source", "signature-url"), "source-signature-url-invalid", v)
+	}
+
+	if len(keys) == 0 {
+		l.emit(at("source", "signature-url"), "source-signature-keys-empty")
 	}
 }
 
 func (l *linter) lintControl(v *recipe.Control) {
 	if v == nil {
-		l.emit("control-empty")
+		l.emit(at("control"), "control-empty")
 		return
 	}
 
 	l.lintControlDescription(v.Description)
+	l.lintControlFields(v.Fields)
+	l.lintControlMultiArch(v.MultiArch)
 }
 
 func (l *linter) lintControlDescription(v string) {
 	var max int
 
 	if v == "" {
-		l.emit("control-description-empty")
+		l.emit(at("control", "description"), "control-description-empty")
 		return
 	}
 
@@ -222,13 +438,80 @@ func (l *linter) lintControlDescription(v string) {
 	}
 
 	if max > 0 {
-		l.emit("control-description-wrap", max)
+		l.emit(at("control", "description"), "control-description-wrap", max)
+	}
+}
+
+func (l *linter) lintControlFields(v map[string]string) {
+	keys := []string{}
+	for key := range v {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !recipe.ValidControlField(key) {
+			l.emit(at("control", "fields", key), "control-field-invalid", key)
+		}
+	}
+}
+
+func (l *linter) lintControlMultiArch(v string) {
+	switch v {
+	case "", deb.MultiArchAllowed, deb.MultiArchForeign, deb.MultiArchNo, deb.MultiArchSame:
+	default:
+		l.emit(at("control", "multi-arch"), "control-multi-arch-invalid", v)
+	}
+}
+
+func (l *linter) lintControlRelations(name string, v *recipe.Control) {
+	for _, field := range []struct {
+		key   string
+		value []string
+		self  bool
+	}{
+		{"depends", v.Depends, true},
+		{"pre-depends", v.PreDepends, true},
+		{"recommends", v.Recommends, true},
+		{"suggests", v.Suggests, true},
+		{"enhances", v.Enhances, true},
+		{"breaks", v.Breaks, false},
+		{"conflicts", v.Conflicts, false},
+		{"provides", v.Provides, false},
+		{"replaces", v.Replaces, false},
+		{"built-using", v.BuiltUsing, false},
+	} {
+		for idx, value := range field.value {
+			rendered, err := recipe.Render(value, templateData)
+			if err != nil {
+				l.emit(at("control", field.key, idx), "control-relation-template-invalid", field.key, value)
+				continue
+			}
+
+			relations, err := deb.ParseFieldRelations(field.key, rendered)
+			if err != nil {
+				l.emit(at("control", field.key, idx), "control-relation-invalid", field.key, value)
+				continue
+			}
+
+			if !field.self {
+				continue
+			}
+
+			for _, rel := range relations {
+				for _, dep := range rel {
+					if dep.Name == name {
+						l.emit(at("control", field.key, idx), "control-relation-self", field.key, value)
+					}
+				}
+			}
+		}
 	}
 }
 
 func (l *linter) lintInstall(v *recipe.Install) {
 	if v == nil {
-		l.emit("install-empty")
+		l.emit(at("install"), "install-empty")
 		return
 	}
 
@@ -238,57 +521,274 @@ func (l *linter) lintInstall(v *recipe.Install) {
 
 func (l *linter) lintInstallMap(subkey string, v recipe.InstallMap) {
 	if subkey == "upstream" && v == nil {
-		l.emit("install-upstream-empty")
+		l.emit(at("install", "upstream"), "install-upstream-empty")
 		return
 	}
 
 	for dst, rules := range v {
 		renames := make(map[string]struct{})
 
-		if !filepath.IsAbs(dst) {
-			l.emit("install-destination-relative", dst)
+		path, err := recipe.Render(dst, templateData)
+		if err != nil {
+			l.emit(at("install", subkey, dst), "install-template-invalid", dst)
+		} else if !filepath.IsAbs(path) {
+			l.emit(at("install", subkey, dst), "install-destination-relative", dst)
 		}
 
 		if rules == nil {
-			l.emit("install-rule-empty", dst)
+			l.emit(at("install", subkey, dst), "install-rule-empty", dst)
 		}
 
 		for idx, rule := range rules {
 			if rule.Pattern == "" {
-				l.emit("install-rule-pattern-empty", dst, idx)
+				l.emit(at("install", subkey, dst, idx, "pattern"), "install-rule-pattern-empty", dst, idx)
+			}
+
+			for _, field := range []struct {
+				key   string
+				value string
+			}{
+				{"pattern", rule.Pattern},
+				{"exclude", rule.Exclude},
+				{"rename", rule.Rename},
+			} {
+				if _, err := recipe.Render(field.value, templateData); err != nil {
+					l.emit(at("install", subkey, dst, idx, field.key), "install-template-invalid", field.value)
+				}
 			}
 
 			if rule.Rename != "" {
 				_, ok := renames[rule.Rename]
 				if ok {
-					l.emit("install-rule-rename-duplicate", dst, rule.Rename)
+					l.emit(at("install", subkey, dst, idx, "rename"), "install-rule-rename-duplicate", dst,
+						rule.Rename)
 				} else {
 					renames[rule.Rename] = struct{}{}
 				}
 			}
 
 			if rule.ConfFile && !strings.HasPrefix(dst, "/etc") {
-				l.emit("install-rule-conffile-outside-etc", dst, idx)
+				l.emit(at("install", subkey, dst, idx, "conffile"), "install-rule-conffile-outside-etc", dst, idx)
 			}
 		}
 	}
 }
 
 func (l *linter) lintDirs(v []string) {
-	for _, dir := range v {
-		if !filepath.IsAbs(dir) {
-			l.emit("dirs-path-relative", dir)
+	for idx, dir := range v {
+		path, err := recipe.Render(dir, templateData)
+		if err != nil {
+			l.emit(at("dirs", idx), "dirs-template-invalid", dir)
+		} else if !filepath.IsAbs(path) {
+			l.emit(at("dirs", idx), "dirs-path-relative", dir)
 		}
 	}
 }
 
 func (l *linter) lintLinks(v map[string]string) {
 	for dst, src := range v {
-		if !filepath.IsAbs(dst) {
-			l.emit("links-destination-relative", dst)
+		path, err := recipe.Render(dst, templateData)
+		if err != nil {
+			l.emit(at("links", dst), "links-template-invalid", dst)
+		} else if !filepath.IsAbs(path) {
+			l.emit(at("links", dst), "links-destination-relative", dst)
 		}
-		if !filepath.IsAbs(src) {
-			l.emit("links-source-relative", src)
+
+		path, err = recipe.Render(src, templateData)
+		if err != nil {
+			l.emit(at("links", dst), "links-template-invalid", src)
+		} else if !filepath.IsAbs(path) {
+			l.emit(at("links", dst), "links-source-relative", src)
 		}
 	}
 }
+
+func (l *linter) lintControlFiles(v []recipe.File) {
+	for _, f := range v {
+		name := f.FileInfo.Name()
+
+		script, ok := controlFiles[name]
+		if !ok {
+			l.emitFile(f.Path, 0, "control-file-unknown", name)
+			continue
+		} else if !script {
+			continue
+		}
+
+		if f.FileInfo.Mode()&0111 == 0 {
+			l.emitFile(f.Path, 0, "control-script-not-executable", name)
+		}
+
+		data, err := ioutil.ReadFile(f.Path)
+		if err != nil {
+			continue
+		}
+
+		if !bytes.HasPrefix(data, []byte("#!")) {
+			l.emitFile(f.Path, 1, "control-script-shebang-missing", name)
+			continue
+		}
+
+		// Only check for "set -e" usage in shell scripts, either set in the script body or in the shebang line
+		shebang := strings.Fields(strings.SplitN(string(data[2:]), "\n", 2)[0])
+		if len(shebang) == 0 {
+			l.emitFile(f.Path, 1, "control-script-shebang-missing", name)
+			continue
+		}
+
+		interpreter, opts := filepath.Base(shebang[0]), shebang[1:]
+		if interpreter == "env" && len(opts) > 0 {
+			interpreter, opts = opts[0], opts[1:]
+		}
+
+		if !shells[interpreter] {
+			continue
+		}
+
+		found := setERegexp.Match(data)
+		for _, opt := range opts {
+			if strings.HasPrefix(opt, "-") && !strings.HasPrefix(opt, "--") && strings.Contains(opt, "e") {
+				found = true
+			}
+		}
+
+		if !found {
+			l.emitFile(f.Path, 0, "control-script-set-e-missing", name)
+		}
+	}
+}
+
+func (l *linter) lintRecipeFiles(rcp *recipe.Recipe) {
+	// Consider architecture-specific rules too, as files might only be installed for some architectures
+	maps := []recipe.InstallMap{}
+	for _, r := range append([]*recipe.Recipe{rcp}, archRecipes(rcp)...) {
+		if r.Install != nil {
+			maps = append(maps, r.Install.Recipe)
+		}
+	}
+
+	for _, f := range rcp.RecipeFiles {
+		name := f.FileInfo.Name()
+
+		matched := false
+		for _, m := range maps {
+			for _, rules := range m {
+				for _, rule := range rules {
+					if templated(rule.Pattern) || rule.Match(name) {
+						matched = true
+					}
+				}
+			}
+		}
+
+		if !matched {
+			l.emitFile(f.Path, 0, "files-unmatched", name)
+		}
+	}
+
+	if rcp.Install == nil {
+		return
+	}
+
+	dsts := make([]string, 0, len(rcp.Install.Recipe))
+	for dst := range rcp.Install.Recipe {
+		dsts = append(dsts, dst)
+	}
+	sort.Strings(dsts)
+
+	for _, dst := range dsts {
+		for idx, rule := range rcp.Install.Recipe[dst] {
+			// Templated patterns can't be checked as their rendering depends on the package being built
+			if rule.Pattern == "" || templated(rule.Pattern) {
+				continue
+			}
+
+			matched := false
+			for _, f := range rcp.RecipeFiles {
+				if rule.Match(f.FileInfo.Name()) {
+					matched = true
+					break
+				}
+			}
+
+			if !matched {
+				l.emit(at("install", "recipe", dst, idx, "pattern"), "install-rule-unmatched", dst, idx)
+			}
+		}
+	}
+}
+
+func (l *linter) lintArchOverrides(rcp *recipe.Recipe) {
+	archs := make([]string, 0, len(rcp.ArchOverrides))
+	for arch := range rcp.ArchOverrides {
+		archs = append(archs, arch)
+	}
+	sort.Strings(archs)
+
+	for _, arch := range archs {
+		if rcp.Source != nil {
+			if _, ok := rcp.Source.ArchMapping[arch]; !ok {
+				l.emit(at("arch-overrides", arch), "arch-overrides-unknown", arch)
+			}
+		}
+
+		// Lint recipe as merged for the architecture, only reporting problems not already found on base recipe
+		ar := rcp.Arch(arch)
+		if ar == rcp {
+			continue
+		}
+
+		sub := &linter{}
+		sub.lintSource(ar.Source)
+		if ar.Source != nil {
+			sub.lintSourceSignature(ar.Source.SignatureURL, ar.KeyFiles)
+		}
+		if ar.Control != nil {
+			sub.lintControlRelations(ar.Name, ar.Control)
+		}
+		sub.lintInstall(ar.Install)
+
+		l.merge(sub, at("arch-overrides", arch))
+	}
+}
+
+func (l *linter) lintIgnore(v []string) {
+	for idx, tag := range v {
+		if _, ok := rules[tag]; !ok {
+			l.emit(at("lint", "ignore", idx), "lint-ignore-unknown", tag)
+		}
+	}
+}
+
+// archRecipes returns the recipes merged with their architecture overrides, sorted by architecture.
+func archRecipes(rcp *recipe.Recipe) []*recipe.Recipe {
+	archs := make([]string, 0, len(rcp.ArchOverrides))
+	for arch := range rcp.ArchOverrides {
+		archs = append(archs, arch)
+	}
+	sort.Strings(archs)
+
+	result := []*recipe.Recipe{}
+	for _, arch := range archs {
+		if ar := rcp.Arch(arch); ar != rcp {
+			result = append(result, ar)
+		}
+	}
+
+	return result
+}
+
+// templated returns whether a value contains template actions.
+func templated(v string) bool {
+	return strings.Contains(v, "{{")
+}
+
+func validURLTemplate(v string) bool {
+	rendered, err := recipe.Render(v, templateData)
+	if err != nil {
+		return false
+	}
+
+	url, err := url.Parse(rendered)
+	return err == nil && url.Scheme != ""
+}
@@ -1,6 +1,7 @@
 package lint
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -29,9 +30,19 @@ func TestInfo(t *testing.T) {
 	}
 }
 
+func TestInfoTemplateVariables(t *testing.T) {
+	info := Info("install-template-invalid")
+
+	typ := reflect.TypeOf(recipe.TemplateData{})
+	for i := 0; i < typ.NumField(); i++ {
+		assert.Contains(t, info.Description, `".`+typ.Field(i).Name+`"`)
+	}
+}
+
 func TestLint(t *testing.T) {
 	for _, test := range []struct {
 		recipe   string
+		config   *Config
 		problems []*Problem
 		ok       bool
 	}{
@@ -40,14 +51,75 @@ func TestLint(t *testing.T) {
 			ok:     true,
 		},
 		{
-			recipe:   "testdata/invalid-name",
-			problems: []*Problem{{LevelError, "name-invalid", []interface{}{"Foo"}}},
+			recipe: "testdata/invalid-name",
+			problems: []*Problem{{LevelError, "name-invalid", []interface{}{"Foo"},
+				&Position{"testdata/invalid-name/recipe.yaml", 4, 7}}},
+		},
+		{
+			recipe: "testdata/unknown-field",
+			problems: []*Problem{{LevelError, "field-unknown", []interface{}{"control.depend"},
+				&Position{"testdata/unknown-field/recipe.yaml", 19, 3}}},
+		},
+		{
+			recipe: "testdata/arch-overrides",
+			problems: []*Problem{
+				{LevelError, "source-strip-invalid", []interface{}{-1},
+					&Position{"testdata/arch-overrides/recipe.yaml", 57, 14}},
+				{LevelError, "control-relation-self", []interface{}{"depends", "foo"},
+					&Position{"testdata/arch-overrides/recipe.yaml", 60, 9}},
+				{LevelWarning, "arch-overrides-unknown", []interface{}{"s390x"},
+					&Position{"testdata/arch-overrides/recipe.yaml", 61, 3}},
+			},
+		},
+		{
+			recipe: "testdata/arch-overrides",
+			config: &Config{
+				Disable: []string{"source-strip-invalid", "control-relation-self"},
+				Promote: []string{"arch-overrides-unknown"},
+			},
+			problems: []*Problem{
+				{LevelError, "arch-overrides-unknown", []interface{}{"s390x"},
+					&Position{"testdata/arch-overrides/recipe.yaml", 61, 3}},
+			},
+		},
+		{
+			recipe: "testdata/recipe-files",
+			problems: []*Problem{
+				{LevelError, "control-file-unknown", []interface{}{"foo"},
+					&Position{"testdata/recipe-files/control/foo", 0, 0}},
+				{LevelError, "control-script-not-executable", []interface{}{"postrm"},
+					&Position{"testdata/recipe-files/control/postrm", 0, 0}},
+				{LevelError, "control-script-shebang-missing", []interface{}{"preinst"},
+					&Position{"testdata/recipe-files/control/preinst", 1, 0}},
+				{LevelWarning, "control-script-set-e-missing", []interface{}{"prerm"},
+					&Position{"testdata/recipe-files/control/prerm", 0, 0}},
+				{LevelWarning, "files-unmatched", []interface{}{"extra"},
+					&Position{"testdata/recipe-files/files/extra", 0, 0}},
+				{LevelWarning, "install-rule-unmatched", []interface{}{"/usr/share/foo", 0},
+					&Position{"testdata/recipe-files/recipe.yaml", 44, 16}},
+			},
+		},
+		{
+			recipe: "testdata/lint-ignore",
+			problems: []*Problem{
+				{LevelWarning, "lint-ignore-unknown", []interface{}{"unknown-rule"},
+					&Position{"testdata/lint-ignore/recipe.yaml", 55, 5}},
+			},
+			ok: true,
+		},
+		{
+			recipe: "testdata/lint-ignore",
+			config: &Config{Strict: true},
+			problems: []*Problem{
+				{LevelError, "lint-ignore-unknown", []interface{}{"unknown-rule"},
+					&Position{"testdata/lint-ignore/recipe.yaml", 55, 5}},
+			},
 		},
 	} {
 		rcp, err := recipe.LoadRecipe(test.recipe)
 		assert.Nil(t, err)
 
-		problems, ok := Lint(rcp)
+		problems, ok := Lint(rcp, test.config)
 		assert.Equal(t, test.problems, problems)
 		assert.Equal(t, test.ok, ok)
 	}
@@ -59,11 +131,15 @@ func TestLintVersion(t *testing.T) {
 		problems []*Problem
 	}{
 		{
-			input: 1,
+			input: 2,
+		},
+		{
+			input:    1,
+			problems: []*Problem{{LevelWarning, "version-outdated", []interface{}{1}, nil}},
 		},
 		{
 			input:    0,
-			problems: []*Problem{{LevelError, "version-unsupported", []interface{}{0}}},
+			problems: []*Problem{{LevelError, "version-unsupported", []interface{}{0}, nil}},
 		},
 	} {
 		l := linter{}
@@ -72,6 +148,27 @@ func TestLintVersion(t *testing.T) {
 	}
 }
 
+func TestLintFields(t *testing.T) {
+	for _, test := range []struct {
+		input    recipe.FieldErrors
+		problems []*Problem
+	}{
+		{},
+		{
+			input: recipe.FieldErrors{
+				{Field: "control.pre-depend", Line: 12, Column: 3, Err: recipe.ErrUnknownField},
+				{Field: "source.strip", Line: 5, Column: 10, Err: recipe.ErrInvalidFieldType},
+			},
+			problems: []*Problem{{LevelError, "field-unknown", []interface{}{"control.pre-depend"},
+				&Position{Line: 12, Column: 3}}},
+		},
+	} {
+		l := linter{}
+		l.lintFields(test.input)
+		assert.Equal(t, test.problems, l.problems)
+	}
+}
+
 func TestLintName(t *testing.T) {
 	for _, test := range []struct {
 		input    string
@@ -86,27 +183,27 @@ func TestLintName(t *testing.T) {
 		{
 			input: "a-rather-quite-long-recipe-name-that-should-trigger-a-linting-warning",
 			problems: []*Problem{{LevelWarning, "name-too-long",
-				[]interface{}{"a-rather-quite-long-recipe-name-that-should-trigger-a-linting-warning"}}},
+				[]interface{}{"a-rather-quite-long-recipe-name-that-should-trigger-a-linting-warning"}, nil}},
 		},
 		{
 			input:    "",
-			problems: []*Problem{{LevelError, "name-empty", nil}},
+			problems: []*Problem{{LevelError, "name-empty", nil, nil}},
 		},
 		{
 			input:    "Invalid",
-			problems: []*Problem{{LevelError, "name-invalid", []interface{}{"Invalid"}}},
+			problems: []*Problem{{LevelError, "name-invalid", []interface{}{"Invalid"}, nil}},
 		},
 		{
 			input:    "!invalid",
-			problems: []*Problem{{LevelError, "name-invalid", []interface{}{"!invalid"}}},
+			problems: []*Problem{{LevelError, "name-invalid", []interface{}{"!invalid"}, nil}},
 		},
 		{
 			input:    "-invalid",
-			problems: []*Problem{{LevelError, "name-invalid", []interface{}{"-invalid"}}},
+			problems: []*Problem{{LevelError, "name-invalid", []interface{}{"-invalid"}, nil}},
 		},
 		{
 			input:    "invalid-",
-			problems: []*Problem{{LevelError, "name-invalid", []interface{}{"invalid-"}}},
+			problems: []*Problem{{LevelError, "name-invalid", []interface{}{"invalid-"}, nil}},
 		},
 	} {
 		l := linter{}
@@ -125,24 +222,24 @@ func TestLintDescription(t *testing.T) {
 		},
 		{
 			input:    "",
-			problems: []*Problem{{LevelError, "description-empty", nil}},
+			problems: []*Problem{{LevelError, "description-empty", nil, nil}},
 		},
 		{
 			input: "a description without uppercase first character",
 			problems: []*Problem{{LevelWarning, "description-missing-uppercase",
-				[]interface{}{"a description without uppercase first character"}}},
+				[]interface{}{"a description without uppercase first character"}, nil}},
 		},
 		{
 			input: "A description with an extra dot.",
 			problems: []*Problem{{LevelWarning, "description-extra-dot",
-				[]interface{}{"A description with an extra dot."}}},
+				[]interface{}{"A description with an extra dot."}, nil}},
 		},
 		{
 			input: "A rather quite long recipe description that should trigger a linting warning, " +
 				"but it stills require some extra text for that",
 			problems: []*Problem{{LevelWarning, "description-too-long",
 				[]interface{}{"A rather quite long recipe description that should trigger a linting warning, " +
-					"but it stills require some extra text for that"}}},
+					"but it stills require some extra text for that"}, nil}},
 		},
 	} {
 		l := linter{}
@@ -164,11 +261,11 @@ func TestLintMaintainer(t *testing.T) {
 		},
 		{
 			input:    "",
-			problems: []*Problem{{LevelError, "maintainer-empty", nil}},
+			problems: []*Problem{{LevelError, "maintainer-empty", nil, nil}},
 		},
 		{
 			input:    "Foo Bar",
-			problems: []*Problem{{LevelError, "maintainer-invalid", []interface{}{"Foo Bar"}}},
+			problems: []*Problem{{LevelError, "maintainer-invalid", []interface{}{"Foo Bar"}, nil}},
 		},
 	} {
 		l := linter{}
@@ -187,15 +284,15 @@ func TestLintHomepage(t *testing.T) {
 		},
 		{
 			input:    "",
-			problems: []*Problem{{LevelWarning, "homepage-empty", nil}},
+			problems: []*Problem{{LevelWarning, "homepage-empty", nil, nil}},
 		},
 		{
 			input:    "example.net",
-			problems: []*Problem{{LevelError, "homepage-invalid", []interface{}{"example.net"}}},
+			problems: []*Problem{{LevelError, "homepage-invalid", []interface{}{"example.net"}, nil}},
 		},
 		{
 			input:    "invalid",
-			problems: []*Problem{{LevelError, "homepage-invalid", []interface{}{"invalid"}}},
+			problems: []*Problem{{LevelError, "homepage-invalid", []interface{}{"invalid"}, nil}},
 		},
 	} {
 		l := linter{}
@@ -207,7 +304,7 @@ func TestLintHomepage(t *testing.T) {
 func TestLintSource(t *testing.T) {
 	l := linter{}
 	l.lintSource(nil)
-	assert.Equal(t, []*Problem{{LevelError, "source-empty", nil}}, l.problems)
+	assert.Equal(t, []*Problem{{LevelError, "source-empty", nil, nil}}, l.problems)
 }
 
 func TestLintSourceURL(t *testing.T) {
@@ -220,17 +317,17 @@ func TestLintSourceURL(t *testing.T) {
 		},
 		{
 			input:    "",
-			problems: []*Problem{{LevelError, "source-url-empty", nil}},
+			problems: []*Problem{{LevelError, "source-url-empty", nil, nil}},
 		},
 		{
 			input: "https://example.net/path/to/archive-{{ .Version }}_{{ .Arch",
 			problems: []*Problem{{LevelError, "source-url-invalid",
-				[]interface{}{"https://example.net/path/to/archive-{{ .Version }}_{{ .Arch"}}},
+				[]interface{}{"https://example.net/path/to/archive-{{ .Version }}_{{ .Arch"}, nil}},
 		},
 		{
 			input: "example.net/path/to/archive-{{ .Version }}_{{ .Arch }}.tar.gz",
 			problems: []*Problem{{LevelError, "source-url-invalid",
-				[]interface{}{"example.net/path/to/archive-{{ .Version }}_{{ .Arch }}.tar.gz"}}},
+				[]interface{}{"example.net/path/to/archive-{{ .Version }}_{{ .Arch }}.tar.gz"}, nil}},
 		},
 	} {
 		l := linter{}
@@ -255,7 +352,7 @@ func TestLintSourceType(t *testing.T) {
 		},
 		{
 			input:    "invalid",
-			problems: []*Problem{{LevelError, "source-type-invalid", []interface{}{"invalid"}}},
+			problems: []*Problem{{LevelError, "source-type-invalid", []interface{}{"invalid"}, nil}},
 		},
 	} {
 		l := linter{}
@@ -277,7 +374,7 @@ func TestLintSourceStrip(t *testing.T) {
 		},
 		{
 			input:    -1,
-			problems: []*Problem{{LevelError, "source-strip-invalid", []interface{}{-1}}},
+			problems: []*Problem{{LevelError, "source-strip-invalid", []interface{}{-1}, nil}},
 		},
 	} {
 		l := linter{}
@@ -286,10 +383,131 @@ func TestLintSourceStrip(t *testing.T) {
 	}
 }
 
+func TestLintSourceChecksums(t *testing.T) {
+	for _, test := range []struct {
+		input       map[string]map[string]string
+		checksumURL string
+		problems    []*Problem
+	}{
+		{
+			input: map[string]map[string]string{
+				"1.2.3": {"amd64": "sha256:b5bb9d8014a0f9b1d61e21e796d78dccdf1352f23cd32812f4850b878ae4944c"},
+			},
+		},
+		{
+			checksumURL: "https://example.net/path/to/archive-{{ .Version }}.sha256",
+		},
+		{
+			problems: []*Problem{{LevelWarning, "source-checksum-empty", nil, nil}},
+		},
+		{
+			input: map[string]map[string]string{
+				"1.2.3": {"amd64": "b5bb9d8014a0f9b1d61e21e796d78dccdf1352f23cd32812f4850b878ae4944c"},
+			},
+			problems: []*Problem{{LevelError, "source-checksum-invalid", []interface{}{"1.2.3", "amd64",
+				"b5bb9d8014a0f9b1d61e21e796d78dccdf1352f23cd32812f4850b878ae4944c"}, nil}},
+		},
+		{
+			input: map[string]map[string]string{
+				"1.2.3": {"amd64": "md5:d3b07384d113edec49eaa6238ad5ff00"},
+			},
+			problems: []*Problem{{LevelError, "source-checksum-invalid", []interface{}{"1.2.3", "amd64",
+				"md5:d3b07384d113edec49eaa6238ad5ff00"}, nil}},
+		},
+		{
+			checksumURL: "example.net/path/to/archive-{{ .Version }}.sha256",
+			problems: []*Problem{{LevelError, "source-checksum-url-invalid",
+				[]interface{}{"example.net/path/to/archive-{{ .Version }}.sha256"}, nil}},
+		},
+	} {
+		l := linter{}
+		l.lintSourceChecksums(test.input, test.checksumURL)
+		assert.Equal(t, test.problems, l.problems)
+	}
+}
+
+func TestLintSourceLatest(t *testing.T) {
+	for _, test := range []struct {
+		input    *recipe.Latest
+		problems []*Problem
+	}{
+		{
+			input: &recipe.Latest{Type: "github", Repository: "foo/bar"},
+		},
+		{
+			input: &recipe.Latest{Type: "html", URL: "https://example.net/releases/", Pattern: `foo-([0-9.]+)\.tar`},
+		},
+		{
+			input: &recipe.Latest{Type: "json", URL: "https://example.net/releases.json", Path: "releases.*.version"},
+		},
+		{
+			input:    &recipe.Latest{Type: "foo"},
+			problems: []*Problem{{LevelError, "source-latest-type-invalid", []interface{}{"foo"}, nil}},
+		},
+		{
+			input:    &recipe.Latest{Type: "github", Repository: "foo"},
+			problems: []*Problem{{LevelError, "source-latest-repository-invalid", []interface{}{"foo"}, nil}},
+		},
+		{
+			input: &recipe.Latest{Type: "html", URL: "example.net/releases/"},
+			problems: []*Problem{
+				{LevelError, "source-latest-url-invalid", []interface{}{"example.net/releases/"}, nil},
+				{LevelError, "source-latest-pattern-empty", nil, nil},
+			},
+		},
+		{
+			input: &recipe.Latest{Type: "json", URL: "https://example.net/releases.json", Pattern: "v[0-9.]+"},
+			problems: []*Problem{
+				{LevelError, "source-latest-pattern-invalid", []interface{}{"v[0-9.]+"}, nil},
+			},
+		},
+		{
+			input: &recipe.Latest{Type: "github", Repository: "foo/bar", Pattern: "v(.+"},
+			problems: []*Problem{
+				{LevelError, "source-latest-pattern-invalid", []interface{}{"v(.+"}, nil},
+			},
+		},
+	} {
+		l := linter{}
+		l.lintSourceLatest(test.input)
+		assert.Equal(t, test.problems, l.problems, "value: %+v", test.input)
+	}
+}
+
+func TestLintSourceSignature(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		keys     []recipe.File
+		problems []*Problem
+	}{
+		{
+			input: "",
+		},
+		{
+			input: "https://example.net/path/to/archive-{{ .Version }}.tar.gz.asc",
+			keys:  []recipe.File{{Path: "keys/foo.asc"}},
+		},
+		{
+			input:    "https://example.net/path/to/archive-{{ .Version }}.tar.gz.asc",
+			problems: []*Problem{{LevelError, "source-signature-keys-empty", nil, nil}},
+		},
+		{
+			input: "example.net/path/to/archive-{{ .Version }}.tar.gz.asc",
+			keys:  []recipe.File{{Path: "keys/foo.asc"}},
+			problems: []*Problem{{LevelError, "source-signature-url-invalid",
+				[]interface{}{"example.net/path/to/archive-{{ .Version }}.tar.gz.asc"}, nil}},
+		},
+	} {
+		l := linter{}
+		l.lintSourceSignature(test.input, test.keys)
+		assert.Equal(t, test.problems, l.problems, "value: %q", test.input)
+	}
+}
+
 func TestLintControl(t *testing.T) {
 	l := linter{}
 	l.lintControl(nil)
-	assert.Equal(t, []*Problem{{LevelError, "control-empty", nil}}, l.problems)
+	assert.Equal(t, []*Problem{{LevelError, "control-empty", nil, nil}}, l.problems)
 }
 
 func TestLintControlDescription(t *testing.T) {
@@ -302,11 +520,11 @@ func TestLintControlDescription(t *testing.T) {
 		},
 		{
 			input:    "",
-			problems: []*Problem{{LevelError, "control-description-empty", nil}},
+			problems: []*Problem{{LevelError, "control-description-empty", nil, nil}},
 		},
 		{
 			input:    "A long package description providing us with information on the upstream software.",
-			problems: []*Problem{{LevelWarning, "control-description-wrap", []interface{}{82}}},
+			problems: []*Problem{{LevelWarning, "control-description-wrap", []interface{}{82}, nil}},
 		},
 	} {
 		l := linter{}
@@ -315,10 +533,114 @@ func TestLintControlDescription(t *testing.T) {
 	}
 }
 
+func TestLintControlFields(t *testing.T) {
+	for _, test := range []struct {
+		input    map[string]string
+		problems []*Problem
+	}{
+		{
+			input: map[string]string{"X-Foo": "foo"},
+		},
+		{
+			input: map[string]string{"Foo": "foo", "X-Bar Baz": "foo", "X-Foo": "foo"},
+			problems: []*Problem{
+				{LevelError, "control-field-invalid", []interface{}{"Foo"}, nil},
+				{LevelError, "control-field-invalid", []interface{}{"X-Bar Baz"}, nil},
+			},
+		},
+	} {
+		l := linter{}
+		l.lintControlFields(test.input)
+		assert.Equal(t, test.problems, l.problems, "value: %v", test.input)
+	}
+}
+
+func TestLintControlRelations(t *testing.T) {
+	for _, test := range []struct {
+		input    *recipe.Control
+		problems []*Problem
+	}{
+		{
+			input: &recipe.Control{
+				Depends:   []string{"libc6 (>= 2.17)", "bar | baz"},
+				Conflicts: []string{"foo"},
+			},
+		},
+		{
+			input: &recipe.Control{
+				Depends:    []string{"libc6 (>= 2.17"},
+				Recommends: []string{"bar (> 1.0)"},
+			},
+			problems: []*Problem{
+				{LevelError, "control-relation-invalid", []interface{}{"depends", "libc6 (>= 2.17"}, nil},
+				{LevelError, "control-relation-invalid", []interface{}{"recommends", "bar (> 1.0)"}, nil},
+			},
+		},
+		{
+			input: &recipe.Control{
+				Provides:   []string{"bar (= 1.0)", "baz (>= 1.0)", "qux [amd64]"},
+				BuiltUsing: []string{"libc6 (= 2.17)", "gcc-9"},
+			},
+			problems: []*Problem{
+				{LevelError, "control-relation-invalid", []interface{}{"provides", "baz (>= 1.0)"}, nil},
+				{LevelError, "control-relation-invalid", []interface{}{"provides", "qux [amd64]"}, nil},
+				{LevelError, "control-relation-invalid", []interface{}{"built-using", "gcc-9"}, nil},
+			},
+		},
+		{
+			input: &recipe.Control{
+				PreDepends: []string{"bar | foo (>= 1.0)"},
+			},
+			problems: []*Problem{{LevelError, "control-relation-self", []interface{}{"pre-depends",
+				"bar | foo (>= 1.0)"}, nil}},
+		},
+		{
+			input: &recipe.Control{
+				Depends:  []string{"foo-data (= {{ .Version }})"},
+				Provides: []string{"bar-{{ .DebArch }}"},
+				Breaks:   []string{"bar (<< {{ .Version }})"},
+			},
+		},
+		{
+			input: &recipe.Control{
+				Depends: []string{"foo-data (= {{ .Foo }})"},
+			},
+			problems: []*Problem{{LevelError, "control-relation-template-invalid", []interface{}{"depends",
+				"foo-data (= {{ .Foo }})"}, nil}},
+		},
+	} {
+		l := linter{}
+		l.lintControlRelations("foo", test.input)
+		assert.Equal(t, test.problems, l.problems, "value: %+v", test.input)
+	}
+}
+
+func TestLintControlMultiArch(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		problems []*Problem
+	}{
+		{
+			input: "",
+		},
+		{
+			input: "foreign",
+		},
+		{
+			input:    "foo",
+			problems: []*Problem{{LevelError, "control-multi-arch-invalid", []interface{}{"foo"}, nil}},
+		},
+	} {
+		l := linter{}
+		l.lintControlMultiArch(test.input)
+		assert.Equal(t, test.problems, l.problems, "value: %q", test.input)
+	}
+}
+
 func TestLintInstall(t *testing.T) {
 	l := linter{}
 	l.lintInstall(nil)
-	assert.Equal(t, []*Problem{{LevelError, "install-empty", nil}}, l.problems)
+	assert.Equal(t, []*Problem{{LevelError, "install-empty", nil, nil}}, l.problems)
 }
 
 func TestLintInstallMap(t *testing.T) {
@@ -335,35 +657,36 @@ func TestLintInstallMap(t *testing.T) {
 		},
 		{
 			subkey:   "upstream",
-			problems: []*Problem{{LevelError, "install-upstream-empty", nil}},
+			problems: []*Problem{{LevelError, "install-upstream-empty", nil, nil}},
 		},
 		{
 			subkey: "upstream",
 			input: recipe.InstallMap{
 				"path/to/folder": []recipe.InstallRule{{Pattern: "*"}},
 			},
-			problems: []*Problem{{LevelError, "install-destination-relative", []interface{}{"path/to/folder"}}},
+			problems: []*Problem{{LevelError, "install-destination-relative", []interface{}{"path/to/folder"}, nil}},
 		},
 		{
 			subkey: "upstream",
 			input: recipe.InstallMap{
 				"/path/to/folder": nil,
 			},
-			problems: []*Problem{{LevelError, "install-rule-empty", []interface{}{"/path/to/folder"}}},
+			problems: []*Problem{{LevelError, "install-rule-empty", []interface{}{"/path/to/folder"}, nil}},
 		},
 		{
 			subkey: "upstream",
 			input: recipe.InstallMap{
 				"/path/to/folder": []recipe.InstallRule{{}},
 			},
-			problems: []*Problem{{LevelError, "install-rule-pattern-empty", []interface{}{"/path/to/folder", 0}}},
+			problems: []*Problem{{LevelError, "install-rule-pattern-empty", []interface{}{"/path/to/folder", 0}, nil}},
 		},
 		{
 			subkey: "upstream",
 			input: recipe.InstallMap{
 				"/path/to/folder": []recipe.InstallRule{{Pattern: "foo", Rename: "a"}, {Pattern: "bar", Rename: "a"}},
 			},
-			problems: []*Problem{{LevelError, "install-rule-rename-duplicate", []interface{}{"/path/to/folder", "a"}}},
+			problems: []*Problem{{LevelError, "install-rule-rename-duplicate", []interface{}{"/path/to/folder", "a"},
+				nil}},
 		},
 		{
 			subkey: "upstream",
@@ -371,7 +694,33 @@ func TestLintInstallMap(t *testing.T) {
 				"/path/to/folder": []recipe.InstallRule{{Pattern: "foo", ConfFile: true}},
 			},
 			problems: []*Problem{{LevelWarning, "install-rule-conffile-outside-etc",
-				[]interface{}{"/path/to/folder", 0}}},
+				[]interface{}{"/path/to/folder", 0}, nil}},
+		},
+		{
+			subkey: "upstream",
+			input: recipe.InstallMap{
+				"/opt/{{ .Name }}": []recipe.InstallRule{
+					{Pattern: "foo-{{ .Version }}/*", Exclude: "*.{{ .DebArch }}"},
+				},
+			},
+		},
+		{
+			subkey: "upstream",
+			input: recipe.InstallMap{
+				"{{ .Name }}": []recipe.InstallRule{{Pattern: "foo-{{ .Version }/*", Rename: "{{ .Foo }}"}},
+			},
+			problems: []*Problem{
+				{LevelError, "install-destination-relative", []interface{}{"{{ .Name }}"}, nil},
+				{LevelError, "install-template-invalid", []interface{}{"foo-{{ .Version }/*"}, nil},
+				{LevelError, "install-template-invalid", []interface{}{"{{ .Foo }}"}, nil},
+			},
+		},
+		{
+			subkey: "upstream",
+			input: recipe.InstallMap{
+				"/opt/{{ .Name }": []recipe.InstallRule{{Pattern: "foo"}},
+			},
+			problems: []*Problem{{LevelError, "install-template-invalid", []interface{}{"/opt/{{ .Name }"}, nil}},
 		},
 	} {
 		l := linter{}
@@ -390,7 +739,14 @@ func TestDirs(t *testing.T) {
 		},
 		{
 			input:    []string{"/path/to/dir", "path/to/another/dir"},
-			problems: []*Problem{{LevelError, "dirs-path-relative", []interface{}{"path/to/another/dir"}}},
+			problems: []*Problem{{LevelError, "dirs-path-relative", []interface{}{"path/to/another/dir"}, nil}},
+		},
+		{
+			input: []string{"/var/lib/{{ .Name }}"},
+		},
+		{
+			input:    []string{"/var/lib/{{ .Name }"},
+			problems: []*Problem{{LevelError, "dirs-template-invalid", []interface{}{"/var/lib/{{ .Name }"}, nil}},
 		},
 	} {
 		l := linter{}
@@ -409,11 +765,18 @@ func TestLinks(t *testing.T) {
 		},
 		{
 			input:    map[string]string{"path/to/link": "/path/to/target"},
-			problems: []*Problem{{LevelError, "links-destination-relative", []interface{}{"path/to/link"}}},
+			problems: []*Problem{{LevelError, "links-destination-relative", []interface{}{"path/to/link"}, nil}},
 		},
 		{
 			input:    map[string]string{"/path/to/link": "path/to/target"},
-			problems: []*Problem{{LevelError, "links-source-relative", []interface{}{"path/to/target"}}},
+			problems: []*Problem{{LevelError, "links-source-relative", []interface{}{"path/to/target"}, nil}},
+		},
+		{
+			input: map[string]string{"/usr/bin/{{ .Name }}": "/opt/{{ .Name }}-{{ .Version }}/bin/{{ .Name }}"},
+		},
+		{
+			input:    map[string]string{"/usr/bin/foo": "/opt/{{ .Foo }}/bin/foo"},
+			problems: []*Problem{{LevelError, "links-template-invalid", []interface{}{"/opt/{{ .Foo }}/bin/foo"}, nil}},
 		},
 	} {
 		l := linter{}
@@ -424,5 +787,5 @@ func TestLinks(t *testing.T) {
 
 func TestLintUnsupportedRule(t *testing.T) {
 	l := linter{}
-	assert.Panics(t, func() { l.emit("unsupported-rule") })
+	assert.Panics(t, func() { l.emit(nil, "unsupported-rule") })
 }
@@ -0,0 +1,75 @@
+package lint
+
+import (
+	"os"
+	"path"
+	"strings"
+
+	"mkdeb.sh/deb"
+)
+
+var binaryDirs = map[string]bool{
+	"/bin":       true,
+	"/sbin":      true,
+	"/usr/bin":   true,
+	"/usr/games": true,
+	"/usr/sbin":  true,
+}
+
+// LintPackage inspects a Debian package contents and tries to detect problems or guidelines violations.
+//
+// Problems are filtered and their levels adjusted given the linting configuration and the rules tags ignored by the
+// package recipe, if any. Default rules levels apply if cfg is nil.
+func LintPackage(r *deb.Reader, cfg *Config, ignore []string) ([]*Problem, bool) {
+	l := &linter{}
+	l.lintPackageFiles(r)
+	l.lintPackageConfFiles(r.ConfFiles)
+
+	return l.result(cfg, ignore)
+}
+
+func (l *linter) lintPackageFiles(r *deb.Reader) {
+	var copyright string
+
+	if r.Control != nil {
+		copyright = "/usr/share/doc/" + r.Control.Name + "/copyright"
+	}
+
+	found := false
+	for _, h := range r.Files {
+		name := path.Clean("/" + h.Name)
+		dir := h.Mode.IsDir()
+
+		if name == copyright {
+			found = true
+		}
+
+		if !dir && strings.HasPrefix(name, "/usr/local/") {
+			l.emit(nil, "package-file-usr-local", name)
+		}
+
+		if h.Mode&os.ModeSymlink == 0 && h.Mode.Perm()&0002 != 0 && !(dir && h.Mode&os.ModeSticky != 0) {
+			l.emit(nil, "package-file-world-writable", name)
+		}
+
+		if h.Mode&(os.ModeSetuid|os.ModeSetgid) != 0 {
+			l.emit(nil, "package-file-setuid", name)
+		}
+
+		if h.Mode.IsRegular() && binaryDirs[path.Dir(name)] && h.Mode.Perm()&0111 == 0 {
+			l.emit(nil, "package-binary-not-executable", name)
+		}
+	}
+
+	if copyright != "" && !found {
+		l.emit(nil, "package-copyright-missing", copyright)
+	}
+}
+
+func (l *linter) lintPackageConfFiles(v []string) {
+	for _, name := range v {
+		if !strings.HasPrefix(path.Clean(name), "/etc/") {
+			l.emit(nil, "package-conffile-outside-etc", name)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package lint
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"mkdeb.sh/archive"
+	"mkdeb.sh/deb"
+)
+
+func TestLintPackage(t *testing.T) {
+	for _, test := range []struct {
+		files     []*archive.Header
+		confFiles []string
+		config    *Config
+		ignore    []string
+		problems  []*Problem
+		ok        bool
+	}{
+		{
+			files: []*archive.Header{
+				{Name: "./usr/bin/", Mode: os.ModeDir | 0755},
+				{Name: "./usr/bin/foo", Mode: 0755},
+				{Name: "./usr/share/doc/foo/copyright", Mode: 0644},
+				{Name: "./var/lib/foo/", Mode: os.ModeDir | os.ModeSticky | 0777},
+				{Name: "./usr/bin/bar", LinkName: "/usr/bin/foo", Mode: os.ModeSymlink | 0777},
+			},
+			confFiles: []string{"/etc/foo.conf"},
+			ok:        true,
+		},
+		{
+			files: []*archive.Header{
+				{Name: "./usr/local/bin/foo", Mode: 0755},
+				{Name: "./usr/share/foo/data", Mode: 0666},
+				{Name: "./usr/bin/foo", Mode: os.ModeSetuid | 0755},
+				{Name: "./usr/sbin/foo", Mode: 0644},
+			},
+			confFiles: []string{"/opt/foo.conf"},
+			problems: []*Problem{
+				{LevelError, "package-file-usr-local", []interface{}{"/usr/local/bin/foo"}, nil},
+				{LevelError, "package-file-world-writable", []interface{}{"/usr/share/foo/data"}, nil},
+				{LevelWarning, "package-file-setuid", []interface{}{"/usr/bin/foo"}, nil},
+				{LevelError, "package-binary-not-executable", []interface{}{"/usr/sbin/foo"}, nil},
+				{LevelWarning, "package-copyright-missing", []interface{}{"/usr/share/doc/foo/copyright"}, nil},
+				{LevelError, "package-conffile-outside-etc", []interface{}{"/opt/foo.conf"}, nil},
+			},
+		},
+		{
+			files:  []*archive.Header{{Name: "./usr/bin/foo", Mode: 0755}},
+			config: &Config{Strict: true},
+			problems: []*Problem{
+				{LevelError, "package-copyright-missing", []interface{}{"/usr/share/doc/foo/copyright"}, nil},
+			},
+		},
+		{
+			files:  []*archive.Header{{Name: "./usr/bin/foo", Mode: 0755}},
+			config: &Config{Disable: []string{"package-copyright-missing"}},
+			ok:     true,
+		},
+		{
+			files:  []*archive.Header{{Name: "./usr/bin/foo", Mode: 0755}},
+			config: &Config{Strict: true},
+			ignore: []string{"package-copyright-missing"},
+			ok:     true,
+		},
+	} {
+		r := &deb.Reader{
+			Control:   &deb.Control{Name: "foo"},
+			ConfFiles: test.confFiles,
+			Files:     test.files,
+		}
+
+		problems, ok := LintPackage(r, test.config, test.ignore)
+		assert.Equal(t, test.problems, problems)
+		assert.Equal(t, test.ok, ok)
+	}
+}
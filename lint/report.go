@@ -0,0 +1,347 @@
+package lint
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Report formats:
+const (
+	FormatCheckstyle = "checkstyle"
+	FormatJSON       = "json"
+	FormatSARIF      = "sarif"
+)
+
+const (
+	checkstyleVersion = "4.3"
+	sarifSchema       = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+	sarifVersion      = "2.1.0"
+)
+
+// Report is a recipe linting report.
+type Report struct {
+	Recipe     string
+	Repository string
+	Problems   []*Problem
+}
+
+// String returns the problem message, composed of its tag followed by its arguments.
+func (p *Problem) String() string {
+	s := []string{p.Tag}
+	for _, arg := range p.Args {
+		format := "%v"
+
+		switch arg.(type) {
+		case string, fmt.Stringer:
+			format = "%q"
+		}
+
+		s = append(s, fmt.Sprintf(format, arg))
+	}
+
+	return strings.Join(s, " ")
+}
+
+// WriteReports writes linting reports to w in a given format.
+//
+// Problems messages are rendered from their tags and arguments, rules descriptions being written as is, while levels
+// are the problems effective ones once the linting configuration has been applied.
+func WriteReports(w io.Writer, format string, reports []*Report) error {
+	switch format {
+	case FormatCheckstyle:
+		return writeCheckstyle(w, reports)
+
+	case FormatJSON:
+		return writeJSON(w, reports)
+
+	case FormatSARIF:
+		return writeSARIF(w, reports)
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+}
+
+type jsonProblem struct {
+	Recipe     string `json:"recipe"`
+	Repository string `json:"repository,omitempty"`
+	Tag        string `json:"tag"`
+	Level      string `json:"level"`
+
+	// Description is the rule static description, Message being the problem rendered one.
+	Description string        `json:"description"`
+	Message     string        `json:"message"`
+	Args        []interface{} `json:"args"`
+
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+func writeJSON(w io.Writer, reports []*Report) error {
+	problems := []*jsonProblem{}
+
+	for _, report := range reports {
+		for _, p := range report.Problems {
+			jp := &jsonProblem{
+				Recipe:      report.Recipe,
+				Repository:  report.Repository,
+				Tag:         p.Tag,
+				Level:       levelName(p.Level),
+				Description: description(p.Tag),
+				Message:     p.String(),
+				Args:        jsonArgs(p.Args),
+			}
+
+			if p.Position != nil {
+				jp.File, jp.Line, jp.Column = p.Position.File, p.Position.Line, p.Position.Column
+			}
+
+			problems = append(problems, jp)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(problems)
+}
+
+type sarifLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool struct {
+		Driver struct {
+			Name  string       `json:"name"`
+			Rules []*sarifRule `json:"rules"`
+		} `json:"driver"`
+	} `json:"tool"`
+	Results []*sarifResult `json:"results"`
+}
+
+type sarifRule struct {
+	ID                   string        `json:"id"`
+	ShortDescription     sarifMessage  `json:"shortDescription"`
+	FullDescription      sarifMessage  `json:"fullDescription"`
+	DefaultConfiguration sarifRuleConf `json:"defaultConfiguration"`
+}
+
+type sarifRuleConf struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string                 `json:"ruleId"`
+	RuleIndex  int                    `json:"ruleIndex"`
+	Level      string                 `json:"level"`
+	Message    sarifMessage           `json:"message"`
+	Locations  []*sarifLocation       `json:"locations,omitempty"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region *sarifRegion `json:"region,omitempty"`
+	} `json:"physicalLocation"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+func writeSARIF(w io.Writer, reports []*Report) error {
+	run := &sarifRun{Results: []*sarifResult{}}
+	run.Tool.Driver.Name = "mkdeb"
+	run.Tool.Driver.Rules = []*sarifRule{}
+
+	ruleIndexes := map[string]int{}
+
+	for _, report := range reports {
+		for _, p := range report.Problems {
+			idx, ok := ruleIndexes[p.Tag]
+			if !ok {
+				desc := description(p.Tag)
+
+				idx = len(run.Tool.Driver.Rules)
+				ruleIndexes[p.Tag] = idx
+
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, &sarifRule{
+					ID:                   p.Tag,
+					ShortDescription:     sarifMessage{strings.SplitN(desc, "\n\n", 2)[0]},
+					FullDescription:      sarifMessage{desc},
+					DefaultConfiguration: sarifRuleConf{levelName(defaultLevel(p))},
+				})
+			}
+
+			result := &sarifResult{
+				RuleID:    p.Tag,
+				RuleIndex: idx,
+				Level:     levelName(p.Level),
+				Message:   sarifMessage{report.Recipe + ": " + p.String()},
+				Properties: map[string]interface{}{
+					"recipe": report.Recipe,
+					"args":   jsonArgs(p.Args),
+				},
+			}
+
+			if report.Repository != "" {
+				result.Properties["repository"] = report.Repository
+			}
+
+			if p.Position != nil {
+				loc := &sarifLocation{}
+				loc.PhysicalLocation.ArtifactLocation.URI = p.Position.File
+				if p.Position.Line > 0 {
+					loc.PhysicalLocation.Region = &sarifRegion{p.Position.Line, p.Position.Column}
+				}
+
+				result.Locations = []*sarifLocation{loc}
+			}
+
+			run.Results = append(run.Results, result)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(&sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []*sarifRun{run},
+	})
+}
+
+type checkstyleReport struct {
+	XMLName xml.Name          `xml:"checkstyle"`
+	Version string            `xml:"version,attr"`
+	Files   []*checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string             `xml:"name,attr"`
+	Errors []*checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+func writeCheckstyle(w io.Writer, reports []*Report) error {
+	files := map[string]*checkstyleFile{}
+
+	for _, report := range reports {
+		for _, p := range report.Problems {
+			var name string
+			line, column := 0, 0
+
+			if p.Position != nil {
+				name, line, column = p.Position.File, p.Position.Line, p.Position.Column
+			}
+
+			// Checkstyle requires a file for each error, fall back on recipe name if position is unknown
+			if name == "" {
+				name = report.Recipe
+			}
+
+			if _, ok := files[name]; !ok {
+				files[name] = &checkstyleFile{Name: name}
+			}
+
+			files[name].Errors = append(files[name].Errors, &checkstyleError{
+				Line:     line,
+				Column:   column,
+				Severity: levelName(p.Level),
+				Message:  report.Recipe + ": " + p.String(),
+				Source:   "mkdeb.lint." + p.Tag,
+			})
+		}
+	}
+
+	result := &checkstyleReport{Version: checkstyleVersion}
+	for _, file := range files {
+		result.Files = append(result.Files, file)
+	}
+
+	sort.Slice(result.Files, func(i, j int) bool {
+		return result.Files[i].Name < result.Files[j].Name
+	})
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	err = enc.Encode(result)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+
+	return err
+}
+
+// description returns a linting rule description, without surrounding blank lines.
+func description(tag string) string {
+	if info, ok := rules[tag]; ok {
+		return strings.TrimSpace(info.Description)
+	}
+
+	return ""
+}
+
+// defaultLevel returns the default level of a problem rule, prior to any linting configuration being applied.
+func defaultLevel(p *Problem) int {
+	if info, ok := rules[p.Tag]; ok {
+		return info.Level
+	}
+
+	return p.Level
+}
+
+// jsonArgs converts problem arguments for JSON encoding, using the string representation of Stringer values.
+func jsonArgs(args []interface{}) []interface{} {
+	result := make([]interface{}, len(args))
+	for idx, arg := range args {
+		if s, ok := arg.(fmt.Stringer); ok {
+			arg = s.String()
+		}
+
+		result[idx] = arg
+	}
+
+	return result
+}
+
+func levelName(level int) string {
+	if level == LevelError {
+		return "error"
+	}
+
+	return "warning"
+}
@@ -0,0 +1,131 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testReports = []*Report{
+	{
+		Recipe:     "foo",
+		Repository: "mkdeb/mkdeb-core",
+		Problems: []*Problem{
+			{LevelError, "name-invalid", []interface{}{"Foo"}, &Position{"f/foo/recipe.yaml", 4, 7}},
+			{LevelWarning, "homepage-empty", nil, nil},
+		},
+	},
+}
+
+func TestProblemString(t *testing.T) {
+	for _, test := range []struct {
+		input    *Problem
+		expected string
+	}{
+		{
+			input:    &Problem{LevelWarning, "homepage-empty", nil, nil},
+			expected: "homepage-empty",
+		},
+		{
+			input:    &Problem{LevelError, "install-rule-pattern-empty", []interface{}{"/usr/bin", 0}, nil},
+			expected: `install-rule-pattern-empty "/usr/bin" 0`,
+		},
+	} {
+		assert.Equal(t, test.expected, test.input.String())
+	}
+}
+
+func TestWriteReports(t *testing.T) {
+	for _, test := range []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: FormatCheckstyle,
+			expected: `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="4.3">
+  <file name="f/foo/recipe.yaml">
+    <error line="4" column="7" severity="error" message="foo: name-invalid &#34;Foo&#34;" ` +
+				`source="mkdeb.lint.name-invalid"></error>
+  </file>
+  <file name="foo">
+    <error line="0" severity="warning" message="foo: homepage-empty" source="mkdeb.lint.homepage-empty"></error>
+  </file>
+</checkstyle>
+`,
+		},
+		{
+			format: FormatJSON,
+			expected: `[
+  {
+    "recipe": "foo",
+    "repository": "mkdeb/mkdeb-core",
+    "tag": "name-invalid",
+    "level": "error",
+    "description": "Recipe name must be lowercase and consist of letters, digits and hyphens.\n\nHyphens are ` +
+				`only permitted within the name, therefore it must not start or end with an hyphen.",
+    "message": "name-invalid \"Foo\"",
+    "args": [
+      "Foo"
+    ],
+    "file": "f/foo/recipe.yaml",
+    "line": 4,
+    "column": 7
+  },
+  {
+    "recipe": "foo",
+    "repository": "mkdeb/mkdeb-core",
+    "tag": "homepage-empty",
+    "level": "warning",
+    "description": "Recipe homepage should not be empty.",
+    "message": "homepage-empty",
+    "args": []
+  }
+]
+`,
+		},
+	} {
+		buf := bytes.NewBuffer(nil)
+		assert.Nil(t, WriteReports(buf, test.format, testReports))
+		assert.Equal(t, test.expected, buf.String())
+	}
+}
+
+func TestWriteReportsSARIF(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, WriteReports(buf, FormatSARIF, testReports))
+
+	for _, s := range []string{
+		`"version": "2.1.0"`,
+		`"id": "name-invalid"`,
+		`"ruleId": "homepage-empty"`,
+		`"uri": "f/foo/recipe.yaml"`,
+		`"startLine": 4`,
+		`"repository": "mkdeb/mkdeb-core"`,
+	} {
+		assert.Contains(t, buf.String(), s)
+	}
+}
+
+func TestWriteReportsSARIFDefaultLevel(t *testing.T) {
+	var log sarifLog
+
+	// Promoted warnings keep their rule default level
+	reports := []*Report{{Recipe: "foo", Problems: []*Problem{{LevelError, "homepage-empty", nil, nil}}}}
+
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, WriteReports(buf, FormatSARIF, reports))
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &log))
+
+	run := log.Runs[0]
+	assert.Equal(t, "warning", run.Tool.Driver.Rules[0].DefaultConfiguration.Level)
+	assert.Equal(t, "error", run.Results[0].Level)
+}
+
+func TestWriteReportsUnsupported(t *testing.T) {
+	err := WriteReports(bytes.NewBuffer(nil), "unknown", testReports)
+	assert.True(t, errors.Is(err, ErrUnsupportedFormat))
+}
@@ -3,6 +3,14 @@
 package lint
 
 var rules = map[string]*RuleInfo{
+	"arch-overrides-unknown": {
+		Tag:   "arch-overrides-unknown",
+		Level: LevelWarning,
+		Description: `
+Recipe architecture overrides should only be declared for architectures present in the source architecture
+mapping, as they would never be used otherwise.
+`,
+	},
 	"control-empty": {
 		Tag:   "control-empty",
 		Level: LevelError,
@@ -22,6 +30,85 @@ Recipe control description must not be empty.
 		Level: LevelWarning,
 		Description: `
 Recipe control description text should be wrapped at 76 characters.
+`,
+	},
+	"control-field-invalid": {
+		Tag:   "control-field-invalid",
+		Level: LevelError,
+		Description: `
+Recipe control custom field names must be prefixed with "X-" and must not contain spaces or colons.
+
+Example: X-Upstream-Version
+`,
+	},
+	"control-file-unknown": {
+		Tag:   "control-file-unknown",
+		Level: LevelError,
+		Description: `
+Recipe control files must be named after supported package control files: "preinst", "postinst", "prerm",
+"postrm", "config", "templates" or "triggers".
+`,
+	},
+	"control-multi-arch-invalid": {
+		Tag:   "control-multi-arch-invalid",
+		Level: LevelError,
+		Description: `
+Recipe control Multi-Arch value must be either "same", "foreign", "allowed" or "no".
+`,
+	},
+	"control-relation-invalid": {
+		Tag:   "control-relation-invalid",
+		Level: LevelError,
+		Description: `
+Recipe control relations must follow the Debian relationship syntax: alternatives separated by "|", optionally
+followed by a version constraint using one of the "<<", "<=", "=", ">=" or ">>" operators, architecture
+restrictions and build profiles. The deprecated "<" and ">" operators are rejected.
+
+"Provides" relations only allow the "=" operator, without architecture restrictions nor build profiles, and
+"Built-Using" relations require an exact "=" version.
+
+Example: libc6 (>= 2.17) | libc6.1 [amd64]
+`,
+	},
+	"control-relation-self": {
+		Tag:   "control-relation-self",
+		Level: LevelError,
+		Description: `
+Recipe control relations must not declare a dependency on the package itself.
+`,
+	},
+	"control-relation-template-invalid": {
+		Tag:   "control-relation-template-invalid",
+		Level: LevelError,
+		Description: `
+Recipe control relations must be valid templates, only using the available template variables.
+
+Available variables are listed in the "install-template-invalid" rule description.
+
+Example: foo-data (= {{ .Version }})
+`,
+	},
+	"control-script-not-executable": {
+		Tag:   "control-script-not-executable",
+		Level: LevelError,
+		Description: `
+Recipe maintainer scripts must be executable.
+`,
+	},
+	"control-script-set-e-missing": {
+		Tag:   "control-script-set-e-missing",
+		Level: LevelWarning,
+		Description: `
+Recipe shell maintainer scripts should use "set -e", so that they exit as soon as a command fails.
+`,
+	},
+	"control-script-shebang-missing": {
+		Tag:   "control-script-shebang-missing",
+		Level: LevelError,
+		Description: `
+Recipe maintainer scripts must start with a shebang line specifying their interpreter.
+
+Example: #!/bin/sh
 `,
 	},
 	"description-empty": {
@@ -57,6 +144,34 @@ Recipe description should be kept short for readability's sake.
 		Level: LevelError,
 		Description: `
 Recipe directories must be absolute paths.
+`,
+	},
+	"dirs-template-invalid": {
+		Tag:   "dirs-template-invalid",
+		Level: LevelError,
+		Description: `
+Recipe directories must be valid templates, only using the available template variables.
+
+Available variables are listed in the "install-template-invalid" rule description.
+
+Example: /var/lib/{{ .Name }}
+`,
+	},
+	"field-unknown": {
+		Tag:   "field-unknown",
+		Level: LevelError,
+		Description: `
+Recipe must only contain known fields, as unknown ones are either ignored or rejected depending on the recipe
+version. They are usually caused by typos.
+
+Example: "pre-depend" instead of "pre-depends"
+`,
+	},
+	"files-unmatched": {
+		Tag:   "files-unmatched",
+		Level: LevelWarning,
+		Description: `
+Recipe files should be matched by a recipe install rule, as they would never be installed otherwise.
 `,
 	},
 	"homepage-empty": {
@@ -115,6 +230,25 @@ Recipe install rule pattern must not be empty.
 		Level: LevelError,
 		Description: `
 Recipe install rule rename property must be unique.
+`,
+	},
+	"install-rule-unmatched": {
+		Tag:   "install-rule-unmatched",
+		Level: LevelWarning,
+		Description: `
+Recipe install rules should match at least one of the recipe files, as they would be useless otherwise.
+`,
+	},
+	"install-template-invalid": {
+		Tag:   "install-template-invalid",
+		Level: LevelError,
+		Description: `
+Recipe install destinations, patterns, excludes and renames must be valid templates, only using the available
+template variables.
+
+Available variables are ".Name", ".Version", ".Epoch", ".DebArch", ".UpstreamArch" and ".Arch".
+
+Example: {{ .Name }}-{{ .Version }}/bin/{{ .Name }}
 `,
 	},
 	"install-upstream-empty": {
@@ -136,6 +270,24 @@ Recipe links destinations must be absolute paths.
 		Level: LevelError,
 		Description: `
 Recipe links sources must be absolute paths.
+`,
+	},
+	"links-template-invalid": {
+		Tag:   "links-template-invalid",
+		Level: LevelError,
+		Description: `
+Recipe links destinations and sources must be valid templates, only using the available template variables.
+
+Available variables are listed in the "install-template-invalid" rule description.
+
+Example: /opt/{{ .Name }}-{{ .Version }}/bin/{{ .Name }}
+`,
+	},
+	"lint-ignore-unknown": {
+		Tag:   "lint-ignore-unknown",
+		Level: LevelWarning,
+		Description: `
+Recipe linting configuration should only ignore existing rules tags.
 `,
 	},
 	"maintainer-empty": {
@@ -173,6 +325,78 @@ Hyphens are only permitted within the name, therefore it must not start or end w
 		Level: LevelWarning,
 		Description: `
 Recipe name should be kept short for readability's sake.
+`,
+	},
+	"package-binary-not-executable": {
+		Tag:   "package-binary-not-executable",
+		Level: LevelError,
+		Description: `
+Package files installed in binaries directories (/bin, /sbin, /usr/bin, /usr/games and /usr/sbin) must be
+executable.
+`,
+	},
+	"package-conffile-outside-etc": {
+		Tag:   "package-conffile-outside-etc",
+		Level: LevelError,
+		Description: `
+Package configuration files must be located in /etc.
+`,
+	},
+	"package-copyright-missing": {
+		Tag:   "package-copyright-missing",
+		Level: LevelWarning,
+		Description: `
+Package should ship its copyright information in the /usr/share/doc/<package>/copyright file.
+`,
+	},
+	"package-file-setuid": {
+		Tag:   "package-file-setuid",
+		Level: LevelWarning,
+		Description: `
+Package files should not have the setuid or setgid bit set, unless strictly required.
+`,
+	},
+	"package-file-usr-local": {
+		Tag:   "package-file-usr-local",
+		Level: LevelError,
+		Description: `
+Package must not install files in /usr/local, as it is reserved for the local system administrator.
+`,
+	},
+	"package-file-world-writable": {
+		Tag:   "package-file-world-writable",
+		Level: LevelError,
+		Description: `
+Package files and directories must not be world-writable, except for directories having the sticky bit set.
+`,
+	},
+	"source-checksum-empty": {
+		Tag:   "source-checksum-empty",
+		Level: LevelWarning,
+		Description: `
+Recipe source should declare upstream archives checksums, either using "checksums" or "checksum-url".
+
+Checksums allow to ensure that downloaded upstream archives have not been altered.
+`,
+	},
+	"source-checksum-invalid": {
+		Tag:   "source-checksum-invalid",
+		Level: LevelError,
+		Description: `
+Recipe source checksums must be valid checksums, prefixed with their algorithm.
+
+Currently supported algorithms are "sha256" and "sha512".
+
+Example: sha256:b5bb9d8014a0f9b1d61e21e796d78dccdf1352f23cd32812f4850b878ae4944c
+`,
+	},
+	"source-checksum-url-invalid": {
+		Tag:   "source-checksum-url-invalid",
+		Level: LevelError,
+		Description: `
+Recipe source checksum URL must be a valid URL, including a scheme. It may use template variables.
+
+Example: https://example.net/foo-{{ .Version }}_SHA256SUMS
 `,
 	},
 	"source-empty": {
@@ -180,6 +404,70 @@ Recipe name should be kept short for readability's sake.
 		Level: LevelError,
 		Description: `
 Recipe source must not be empty.
+`,
+	},
+	"source-latest-pattern-empty": {
+		Tag:   "source-latest-pattern-empty",
+		Level: LevelError,
+		Description: `
+Recipe source latest version pattern must not be empty when using the "html" discovery type.
+`,
+	},
+	"source-latest-pattern-invalid": {
+		Tag:   "source-latest-pattern-invalid",
+		Level: LevelError,
+		Description: `
+Recipe source latest version pattern must be a valid regular expression, having at least one capture group.
+
+The first capture group is used to extract the version from the discovered values.
+
+Example: href="foo-([0-9.]+)\.tar\.gz"
+`,
+	},
+	"source-latest-repository-invalid": {
+		Tag:   "source-latest-repository-invalid",
+		Level: LevelError,
+		Description: `
+Recipe source latest version repository must be a GitHub repository, in the "owner/name" form.
+
+Example: foo/bar
+`,
+	},
+	"source-latest-type-invalid": {
+		Tag:   "source-latest-type-invalid",
+		Level: LevelError,
+		Description: `
+Recipe source latest version type must be a valid discovery type.
+
+Currently supported discovery types are "github", "html" and "json".
+`,
+	},
+	"source-latest-url-invalid": {
+		Tag:   "source-latest-url-invalid",
+		Level: LevelError,
+		Description: `
+Recipe source latest version URL must be a valid URL, including a scheme, when using the "html" or "json"
+discovery types.
+
+Example: https://example.net/releases/
+`,
+	},
+	"source-signature-keys-empty": {
+		Tag:   "source-signature-keys-empty",
+		Level: LevelError,
+		Description: `
+Recipe must ship trusted keys in its "keys" directory when a source signature URL is declared.
+
+Supported keys are OpenPGP keys (".asc", ".gpg" or ".pgp" files) and minisign public keys (".pub" files).
+`,
+	},
+	"source-signature-url-invalid": {
+		Tag:   "source-signature-url-invalid",
+		Level: LevelError,
+		Description: `
+Recipe source signature URL must be a valid URL, including a scheme. It may use template variables.
+
+Example: https://example.net/foo-{{ .Version }}_{{ .Arch }}.tar.gz.asc
 `,
 	},
 	"source-strip-invalid": {
@@ -218,6 +506,16 @@ Recipe source URL must not be empty.
 Recipe source URL must be a valid URL, including a scheme. It may use template variables.
 
 Example: https://example.net/foo-{{ .Version }}_{{ .Arch }}.tar.gz
+`,
+	},
+	"version-outdated": {
+		Tag:   "version-outdated",
+		Level: LevelWarning,
+		Description: `
+Recipe version should be the current format version.
+
+Current version is 2, rejecting unknown fields instead of silently ignoring them. Version 1 recipes can be
+migrated using the "mkdeb migrate" command.
 `,
 	},
 	"version-unsupported": {
@@ -226,7 +524,7 @@ Example: https://example.net/foo-{{ .Version }}_{{ .Arch }}.tar.gz
 		Description: `
 Recipe version must be a valid supported version.
 
-Currently supported version is 1.
+Currently supported versions are 1 and 2.
 `,
 	},
 }
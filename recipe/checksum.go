@@ -0,0 +1,146 @@
+package recipe
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+)
+
+// Checksum algorithms:
+const (
+	ChecksumSHA256 = "sha256"
+	ChecksumSHA512 = "sha512"
+)
+
+// Checksum is an upstream archive checksum.
+type Checksum struct {
+	Algorithm string
+	Digest    []byte
+}
+
+// ParseChecksum parses a checksum string representation (e.g. "sha256:<hex digest>").
+func ParseChecksum(s string) (*Checksum, error) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidChecksum
+	}
+
+	return newChecksum(strings.ToLower(parts[0]), parts[1])
+}
+
+// ParseChecksumFile parses a checksums listing file and returns the checksum matching a given file name.
+//
+// Both the GNU coreutils (e.g. "<digest>  <name>") and the BSD (e.g. "SHA256 (<name>) = <digest>") formats are
+// supported. Algorithm is guessed from the digest length for GNU formatted entries.
+func ParseChecksumFile(r io.Reader, name string) (*Checksum, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// BSD format
+		if idx := strings.Index(line, ") = "); idx != -1 {
+			parts := strings.SplitN(line[:idx], " (", 2)
+			if len(parts) != 2 || parts[1] != name {
+				continue
+			}
+
+			return newChecksum(strings.ToLower(parts[0]), line[idx+4:])
+		}
+
+		// GNU coreutils format, file name being optionally prefixed with a star in binary mode
+		fields := strings.Fields(line)
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != name {
+			continue
+		}
+
+		switch len(fields[0]) {
+		case sha256.Size * 2:
+			return newChecksum(ChecksumSHA256, fields[0])
+
+		case sha512.Size * 2:
+			return newChecksum(ChecksumSHA512, fields[0])
+
+		default:
+			return nil, ErrInvalidChecksum
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, ErrChecksumNotFound
+}
+
+func newChecksum(algo, digest string) (*Checksum, error) {
+	c := &Checksum{Algorithm: algo}
+
+	h, err := c.hash()
+	if err != nil {
+		return nil, err
+	}
+
+	c.Digest, err = hex.DecodeString(strings.TrimSpace(digest))
+	if err != nil || len(c.Digest) != h.Size() {
+		return nil, ErrInvalidChecksum
+	}
+
+	return c, nil
+}
+
+// Verify checks whether or not data read from a reader matches the checksum.
+func (c *Checksum) Verify(r io.Reader) error {
+	h, err := c.hash()
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(h, r)
+	if err != nil {
+		return err
+	}
+
+	sum := h.Sum(nil)
+	if !bytes.Equal(sum, c.Digest) {
+		return fmt.Errorf("%w: expected %s, got %s:%x", ErrChecksumMismatch, c, c.Algorithm, sum)
+	}
+
+	return nil
+}
+
+// VerifyFile checks whether or not a file content matches the checksum.
+func (c *Checksum) VerifyFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return c.Verify(f)
+}
+
+func (c *Checksum) String() string {
+	return fmt.Sprintf("%s:%x", c.Algorithm, c.Digest)
+}
+
+func (c *Checksum) hash() (hash.Hash, error) {
+	switch c.Algorithm {
+	case ChecksumSHA256:
+		return sha256.New(), nil
+
+	case ChecksumSHA512:
+		return sha512.New(), nil
+	}
+
+	return nil, ErrUnsupportedChecksum
+}
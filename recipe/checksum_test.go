@@ -0,0 +1,104 @@
+package recipe
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testSHA256 = "b5bb9d8014a0f9b1d61e21e796d78dccdf1352f23cd32812f4850b878ae4944c"
+	testSHA512 = "0cf9180a764aba863a67b6d72f0918bc131c6772642cb2dce5a34f0a702f9470ddc2bf125c12198b1995c233c34b4afd" +
+		"346c54a2334c350a948a51b6e8b4e6b6"
+)
+
+func TestParseChecksum(t *testing.T) {
+	for _, test := range []struct {
+		input     string
+		algorithm string
+		err       error
+	}{
+		{
+			input:     "sha256:" + testSHA256,
+			algorithm: ChecksumSHA256,
+		},
+		{
+			input:     "SHA512:" + testSHA512,
+			algorithm: ChecksumSHA512,
+		},
+		{
+			input: testSHA256,
+			err:   ErrInvalidChecksum,
+		},
+		{
+			input: "sha256:" + testSHA512,
+			err:   ErrInvalidChecksum,
+		},
+		{
+			input: "sha256:invalid",
+			err:   ErrInvalidChecksum,
+		},
+		{
+			input: "md5:d3b07384d113edec49eaa6238ad5ff00",
+			err:   ErrUnsupportedChecksum,
+		},
+	} {
+		c, err := ParseChecksum(test.input)
+		assert.Equal(t, test.err, err, "value: %q", test.input)
+		if test.err == nil {
+			assert.Equal(t, test.algorithm, c.Algorithm)
+			assert.Equal(t, strings.ToLower(test.input), c.String())
+		}
+	}
+}
+
+func TestParseChecksumFile(t *testing.T) {
+	data := `# Checksums
+` + testSHA256 + `  foo-1.2.3.tar.gz
+` + testSHA512 + ` *foo-1.2.3.zip
+SHA256 (foo-1.2.3.tar.xz) = ` + testSHA256 + `
+`
+
+	for _, test := range []struct {
+		name     string
+		expected string
+		err      error
+	}{
+		{
+			name:     "foo-1.2.3.tar.gz",
+			expected: "sha256:" + testSHA256,
+		},
+		{
+			name:     "foo-1.2.3.zip",
+			expected: "sha512:" + testSHA512,
+		},
+		{
+			name:     "foo-1.2.3.tar.xz",
+			expected: "sha256:" + testSHA256,
+		},
+		{
+			name: "foo-1.2.3.tar.bz2",
+			err:  ErrChecksumNotFound,
+		},
+	} {
+		c, err := ParseChecksumFile(strings.NewReader(data), test.name)
+		assert.Equal(t, test.err, err, "name: %q", test.name)
+		if test.err == nil {
+			assert.Equal(t, test.expected, c.String())
+		}
+	}
+}
+
+func TestChecksumVerify(t *testing.T) {
+	c, err := ParseChecksum("sha256:" + testSHA256)
+	assert.Nil(t, err)
+	assert.Nil(t, c.Verify(strings.NewReader("foo\n")))
+	assert.True(t, errors.Is(c.Verify(strings.NewReader("bar\n")), ErrChecksumMismatch))
+
+	c, err = ParseChecksum("sha512:" + testSHA512)
+	assert.Nil(t, err)
+	assert.Nil(t, c.Verify(strings.NewReader("foo\n")))
+	assert.True(t, errors.Is(c.Verify(strings.NewReader("bar\n")), ErrChecksumMismatch))
+}
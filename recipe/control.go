@@ -1,5 +1,7 @@
 package recipe
 
+import "strings"
+
 // Control is a recipe control.
 type Control struct {
 	Section  string `yaml:"section"`
@@ -14,5 +16,30 @@ type Control struct {
 	Enhances    []string `yaml:"enhances"`
 	Breaks      []string `yaml:"breaks"`
 	Conflicts   []string `yaml:"conflicts"`
-	Description string   `yaml:"description"`
+	Provides    []string `yaml:"provides"`
+	Replaces    []string `yaml:"replaces"`
+	BuiltUsing  []string `yaml:"built-using"`
+	Source      string   `yaml:"source"`
+	MultiArch   string   `yaml:"multi-arch"`
+	Essential   bool     `yaml:"essential"`
+	Protected   bool     `yaml:"protected"`
+	Description string   `yaml:"description" schema:"required"`
+
+	// Fields are custom control fields, their names must be prefixed with "X-".
+	Fields map[string]string `yaml:"fields"`
+}
+
+// ValidControlField checks whether or not a custom control field name is valid.
+func ValidControlField(name string) bool {
+	if !strings.HasPrefix(name, "X-") || len(name) == 2 {
+		return false
+	}
+
+	for _, c := range name {
+		if c <= 32 || c >= 127 || c == ':' {
+			return false
+		}
+	}
+
+	return true
 }
@@ -3,6 +3,16 @@ package recipe
 import "errors"
 
 var (
+	// ErrChecksumMismatch is a checksum mismatch error.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrChecksumNotFound is a checksum not found error.
+	ErrChecksumNotFound = errors.New("checksum not found")
+	// ErrInvalidChecksum is an invalid checksum error.
+	ErrInvalidChecksum = errors.New("invalid checksum")
+	// ErrInvalidControlField is an invalid control field error.
+	ErrInvalidControlField = errors.New("invalid control field")
+	// ErrInvalidFieldType is an invalid field type error.
+	ErrInvalidFieldType = errors.New("invalid field type")
 	// ErrMissingControl is a missing control error.
 	ErrMissingControl = errors.New("missing control")
 	// ErrMissingControlDescription is a missing control description error.
@@ -19,6 +29,10 @@ var (
 	ErrMissingSource = errors.New("missing source")
 	// ErrMissingSourceURL is a missing source URL error.
 	ErrMissingSourceURL = errors.New("missing source URL")
+	// ErrUnknownField is an unknown field error.
+	ErrUnknownField = errors.New("unknown field")
+	// ErrUnsupportedChecksum is an unsupported checksum algorithm error.
+	ErrUnsupportedChecksum = errors.New("unsupported checksum algorithm")
 	// ErrUnsupportedVersion is an unsupported version error.
 	ErrUnsupportedVersion = errors.New("unsupported version")
 )
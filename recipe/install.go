@@ -16,3 +16,19 @@ type InstallRule struct {
 	Rename   string `yaml:"rename"`
 	ConfFile bool   `yaml:"conffile"`
 }
+
+// InstallMatch is an installation rule matched by a path.
+type InstallMatch struct {
+	// Base and Index are the installation map destination and the rule index the path has been matched by.
+	Base  string
+	Index int
+	Rule  InstallRule
+
+	// Path is the destination installation path.
+	Path string
+}
+
+// Match returns whether a path matches the installation rule pattern without matching its exclusion one.
+func (r InstallRule) Match(path string) bool {
+	return pathMatch(r.Pattern, r.Exclude, path)
+}
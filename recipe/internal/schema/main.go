@@ -0,0 +1,31 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"log"
+
+	"mkdeb.sh/recipe"
+)
+
+var outputPath string
+
+func main() {
+	flag.StringVar(&outputPath, "o", "", "output file path")
+	flag.Parse()
+
+	if outputPath == "" {
+		log.Fatal(`missing "-o" mandatory option`)
+	}
+
+	data, err := json.MarshalIndent(recipe.NewSchema(), "", "  ")
+	if err != nil {
+		log.Fatalf("cannot marshal schema: %s", err)
+	}
+
+	err = ioutil.WriteFile(outputPath, append(data, '\n'), 0644)
+	if err != nil {
+		log.Fatalf("cannot write output file: %s", err)
+	}
+}
@@ -0,0 +1,65 @@
+package recipe
+
+import (
+	"bytes"
+	"strconv"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// Migrate migrates recipe file content to the current format version, returning the migrated content.
+//
+// Migration from version 1 to version 2 only bumps the version field, as the latter doesn't change fields
+// semantics but rejects unknown fields: these must be fixed beforehand and are returned as errors.
+func Migrate(data []byte) ([]byte, error) {
+	var (
+		node    yaml.Node
+		version *yaml.Node
+	)
+
+	err := yaml.Unmarshal(data, &node)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(node.Content) > 0 && node.Content[0].Kind == yaml.MappingNode {
+		root := node.Content[0]
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value == "version" {
+				version = root.Content[i+1]
+				break
+			}
+		}
+	}
+
+	if version == nil || version.Kind != yaml.ScalarNode {
+		return nil, ErrUnsupportedVersion
+	}
+
+	v, err := strconv.Atoi(version.Value)
+	if err != nil || !VersionSupported(v) {
+		return nil, ErrUnsupportedVersion
+	} else if v == CurrentVersion {
+		return data, nil
+	}
+
+	errs := recipeSchema.Validate(&node)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	// Replace version value in place to preserve the original file formatting and comments
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	line := lines[version.Line-1]
+
+	start := version.Column - 1
+	end := start + len(version.Value)
+
+	buf := bytes.NewBuffer(nil)
+	buf.Write(line[:start])
+	buf.WriteString(strconv.Itoa(CurrentVersion))
+	buf.Write(line[end:])
+	lines[version.Line-1] = buf.Bytes()
+
+	return bytes.Join(lines, nil), nil
+}
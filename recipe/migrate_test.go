@@ -0,0 +1,48 @@
+package recipe
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    "---\n# Comment\nversion: 1 # Format version\n\nname: foo\n",
+			expected: "---\n# Comment\nversion: 2 # Format version\n\nname: foo\n",
+		},
+		{
+			input:    "name: foo\nversion:   1\n",
+			expected: "name: foo\nversion:   2\n",
+		},
+		{
+			input:    "version: 2\nname: foo\n",
+			expected: "version: 2\nname: foo\n",
+		},
+	} {
+		data, err := Migrate([]byte(test.input))
+		assert.Nil(t, err)
+		assert.Equal(t, test.expected, string(data))
+	}
+}
+
+func TestMigrateUnknownField(t *testing.T) {
+	_, err := Migrate([]byte("version: 1\nname: foo\ncontrol:\n  pre-depend:\n  - bar\n"))
+
+	errs, ok := err.(FieldErrors)
+	assert.True(t, ok)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, &FieldError{Field: "control.pre-depend", Line: 4, Column: 3, Err: ErrUnknownField}, errs[0])
+}
+
+func TestMigrateUnsupportedVersion(t *testing.T) {
+	for _, input := range []string{"name: foo\n", "version: 3\n", "version: foo\n", "- foo\n"} {
+		_, err := Migrate([]byte(input))
+		assert.True(t, errors.Is(err, ErrUnsupportedVersion), "input: %q", input)
+	}
+}
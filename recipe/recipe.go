@@ -6,6 +6,8 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	yaml "gopkg.in/yaml.v3"
@@ -15,43 +17,103 @@ const defaultSourceType = "archive"
 
 // Recipe is a packaging recipe.
 type Recipe struct {
-	Version     int               `yaml:"version"`
-	Name        string            `yaml:"name"`
-	Description string            `yaml:"description"`
-	Maintainer  string            `yaml:"maintainer"`
+	Version     int               `yaml:"version" schema:"required"`
+	Name        string            `yaml:"name" schema:"required"`
+	Description string            `yaml:"description" schema:"required"`
+	Maintainer  string            `yaml:"maintainer" schema:"required"`
 	Homepage    string            `yaml:"homepage"`
-	Source      *Source           `yaml:"source"`
-	Control     *Control          `yaml:"control"`
-	Install     *Install          `yaml:"install"`
+	Source      *Source           `yaml:"source" schema:"required"`
+	Control     *Control          `yaml:"control" schema:"required"`
+	Install     *Install          `yaml:"install" schema:"required"`
 	Dirs        []string          `yaml:"dirs"`
 	Links       map[string]string `yaml:"links"`
 
-	ControlFiles []File
-	RecipeFiles  []File
+	// ArchOverrides are architecture-specific overrides, merged with the recipe upon loading: maps are merged
+	// key by key whereas scalar values and lists are replaced.
+	ArchOverrides map[string]*ArchOverride `yaml:"arch-overrides"`
+
+	// Lint is the recipe-specific linting configuration.
+	Lint *Lint `yaml:"lint"`
+
+	// Path is the recipe directory path.
+	Path string `yaml:"-"`
+
+	ControlFiles []File `yaml:"-"`
+	RecipeFiles  []File `yaml:"-"`
+	KeyFiles     []File `yaml:"-"`
+
+	// FieldErrors are the unknown or invalid fields found in the recipe file. They are rejected upon validation
+	// starting with recipe format version 2, whereas they're ignored with version 1.
+	FieldErrors FieldErrors `yaml:"-"`
+
+	archRecipes map[string]*Recipe
+	node        *yaml.Node
+}
+
+// ArchOverride is a recipe architecture-specific override.
+type ArchOverride struct {
+	Source  *Source  `yaml:"source"`
+	Control *Control `yaml:"control"`
+	Install *Install `yaml:"install"`
+}
+
+// Lint is a recipe linting configuration.
+type Lint struct {
+	// Ignore are the linting rules tags to ignore for the recipe.
+	Ignore []string `yaml:"ignore"`
 }
 
 // LoadRecipe loads a packaging recipe given a file path.
 func LoadRecipe(path string) (*Recipe, error) {
-	var r *Recipe
+	var node yaml.Node
 
 	data, err := ioutil.ReadFile(filepath.Join(path, "recipe.yaml"))
 	if err != nil {
 		return nil, err
-	} else if err = yaml.Unmarshal(data, &r); err != nil {
+	} else if err = yaml.Unmarshal(data, &node); err != nil {
 		return nil, err
 	}
 
-	// Set defaults
-	if r.Source == nil {
-		r.Source = &Source{}
+	r, err := decodeRecipe(&node)
+	if err != nil {
+		return nil, err
 	}
 
-	if r.Source.Type == "" {
-		r.Source.Type = defaultSourceType
-	}
+	r.Path = path
+	r.node = &node
+	r.FieldErrors = recipeSchema.Validate(&node)
 
-	if len(r.Source.ArchMapping) == 0 {
-		r.Source.ArchMapping = map[string]string{"all": ""}
+	// Merge architecture-specific overrides, decoding them on top of a distinct recipe instance for each
+	// architecture so that only the fields actually declared get overridden
+	if len(r.ArchOverrides) > 0 {
+		var raw struct {
+			ArchOverrides map[string]yaml.Node `yaml:"arch-overrides"`
+		}
+
+		err = node.Decode(&raw)
+		if err != nil {
+			return nil, err
+		}
+
+		r.archRecipes = make(map[string]*Recipe, len(raw.ArchOverrides))
+
+		for arch, override := range raw.ArchOverrides {
+			ar, err := decodeRecipe(&node)
+			if err != nil {
+				return nil, err
+			}
+
+			err = override.Decode(ar)
+			if err != nil {
+				return nil, fmt.Errorf("cannot merge %q architecture overrides: %w", arch, err)
+			}
+
+			ar.ArchOverrides = nil
+			ar.Path = r.Path
+			ar.node = r.node
+			ar.FieldErrors = r.FieldErrors
+			r.archRecipes[arch] = ar
+		}
 	}
 
 	// Load control and recipe files references from filesystem
@@ -79,25 +141,130 @@ func LoadRecipe(path string) (*Recipe, error) {
 		})
 	}
 
+	files, err = ioutil.ReadDir(filepath.Join(path, "keys"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("cannot read keys directory: %w", err)
+	}
+
+	for _, fi := range files {
+		r.KeyFiles = append(r.KeyFiles, File{
+			Path:     filepath.Join(path, "keys", fi.Name()),
+			FileInfo: fi,
+		})
+	}
+
+	for _, ar := range r.archRecipes {
+		ar.ControlFiles = r.ControlFiles
+		ar.RecipeFiles = r.RecipeFiles
+		ar.KeyFiles = r.KeyFiles
+	}
+
 	return r, nil
 }
 
+// Arch returns the recipe to use for a given architecture, having its overrides merged if any.
+func (r *Recipe) Arch(arch string) *Recipe {
+	ar, ok := r.archRecipes[arch]
+	if !ok {
+		return r
+	}
+
+	return ar
+}
+
+// Position returns the line and column of a recipe field in the recipe file given its path, made of mapping
+// keys and sequence indexes. If the field isn't declared, the position of its closest declared parent is returned.
+//
+// Zero values are returned if the recipe hasn't been loaded from a file.
+func (r *Recipe) Position(path ...string) (int, int) {
+	if r.node == nil || len(r.node.Content) == 0 {
+		return 0, 0
+	}
+
+	node := r.node.Content[0]
+	line, column := node.Line, node.Column
+
+	for _, key := range path {
+		var next, keyNode *yaml.Node
+
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					keyNode, next = node.Content[i], node.Content[i+1]
+					break
+				}
+			}
+
+		case yaml.SequenceNode:
+			idx, err := strconv.Atoi(key)
+			if err == nil && idx >= 0 && idx < len(node.Content) {
+				next = node.Content[idx]
+			}
+		}
+
+		if next == nil {
+			break
+		}
+
+		// Point to scalar values directly, and to keys for collections as they start on the following lines
+		if keyNode != nil && next.Kind != yaml.ScalarNode {
+			line, column = keyNode.Line, keyNode.Column
+		} else {
+			line, column = next.Line, next.Column
+		}
+
+		node = next
+	}
+
+	return line, column
+}
+
 // InstallPath returns the destination installation path, whether it matches a configuration file path.
 //
 // Last returned boolean will be false if the input path doesn't match the installation rules and true otherwise.
 func (r *Recipe) InstallPath(path string, m InstallMap) (string, bool, bool) {
-	for base, rules := range m {
-		for _, rule := range rules {
-			if pathMatch(rule.Pattern, rule.Exclude, path) {
+	match, ok := r.MatchInstall(path, m)
+	if !ok {
+		return "", false, false
+	}
+
+	return match.Path, match.Rule.ConfFile, true
+}
+
+// MatchInstall returns the installation rule matching a path, along with its resulting destination path.
+//
+// Returned boolean will be false if the input path doesn't match the installation rules and true otherwise.
+func (r *Recipe) MatchInstall(path string, m InstallMap) (*InstallMatch, bool) {
+	// Walk destinations in a stable order so that a path matching multiple rules always gets the same result
+	bases := make([]string, 0, len(m))
+	for base := range m {
+		bases = append(bases, base)
+	}
+	sort.Strings(bases)
+
+	for _, base := range bases {
+		for idx, rule := range m[base] {
+			if rule.Match(path) {
 				if rule.Rename != "" {
 					path = rule.Rename
 				}
-				return filepath.Join(base, path), rule.ConfFile, true
+
+				return &InstallMatch{
+					Base:  base,
+					Index: idx,
+					Rule:  rule,
+					Path:  filepath.Join(base, path),
+				}, true
 			}
 		}
 	}
 
-	return "", false, false
+	return nil, false
 }
 
 // Validate checks for recipe validity.
@@ -106,6 +273,9 @@ func (r *Recipe) Validate() error {
 	case !VersionSupported(r.Version):
 		return ErrUnsupportedVersion
 
+	case r.Version >= 2 && len(r.FieldErrors) > 0:
+		return r.FieldErrors
+
 	case r.Name == "":
 		return ErrMissingName
 
@@ -131,9 +301,41 @@ func (r *Recipe) Validate() error {
 		return ErrMissingInstall
 	}
 
+	for name := range r.Control.Fields {
+		if !ValidControlField(name) {
+			return fmt.Errorf("%w: %q", ErrInvalidControlField, name)
+		}
+	}
+
 	return nil
 }
 
+func decodeRecipe(node *yaml.Node) (*Recipe, error) {
+	var r *Recipe
+
+	err := node.Decode(&r)
+	if err != nil {
+		return nil, err
+	} else if r == nil {
+		r = &Recipe{}
+	}
+
+	// Set defaults
+	if r.Source == nil {
+		r.Source = &Source{}
+	}
+
+	if r.Source.Type == "" {
+		r.Source.Type = defaultSourceType
+	}
+
+	if len(r.Source.ArchMapping) == 0 {
+		r.Source.ArchMapping = map[string]string{"all": ""}
+	}
+
+	return r, nil
+}
+
 func pathMatch(pattern, exclude, value string) bool {
 	// Remove slashes from pattern and value as "path.Match" doesn't handle them
 	pattern = strings.Replace(pattern, "/", "\x1e", -1)
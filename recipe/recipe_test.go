@@ -1,6 +1,7 @@
 package recipe
 
 import (
+	"errors"
 	"os"
 	"testing"
 
@@ -12,6 +13,8 @@ func TestRecipeValid(t *testing.T) {
 	assert.Nil(t, err)
 
 	assert.Equal(t, 1, r.Version)
+	assert.Equal(t, "testdata/valid", r.Path)
+	assert.Nil(t, r.FieldErrors)
 	assert.Equal(t, "foo", r.Name)
 	assert.Equal(t, "a great description", r.Description)
 	assert.Equal(t, "Foo Bar <foo@example.org>", r.Maintainer)
@@ -19,8 +22,34 @@ func TestRecipeValid(t *testing.T) {
 
 	// Check for "source" section
 	assert.Equal(t, "https://example.org/path/to/foo-{{ .Version }}.{{ .Arch }}.tar.gz", r.Source.URL)
+	assert.Equal(t, "https://example.org/path/to/foo-{{ .Version }}.{{ .Arch }}.tar.gz.asc", r.Source.SignatureURL)
 	assert.Equal(t, 1, r.Source.Strip)
 	assert.Equal(t, map[string]string{"amd64": "amd64"}, r.Source.ArchMapping)
+	assert.Equal(t, map[string]map[string]string{
+		"1.2.3": {"amd64": "sha256:b5bb9d8014a0f9b1d61e21e796d78dccdf1352f23cd32812f4850b878ae4944c"},
+	}, r.Source.Checksums)
+	assert.Equal(t, &Latest{Type: "github", Repository: "foo/foo", Pattern: "^foo-v(.+)$"}, r.Source.Latest)
+
+	sum, err := r.Source.Checksum("1.2.3", "amd64")
+	assert.Nil(t, err)
+	assert.Equal(t, "sha256:b5bb9d8014a0f9b1d61e21e796d78dccdf1352f23cd32812f4850b878ae4944c", sum.String())
+
+	// Missing versions and architectures fail, unless a checksums listing can be fetched instead
+	for _, test := range []struct{ version, arch string }{{"1.2.4", "amd64"}, {"1.2.3", "arm64"}} {
+		sum, err = r.Source.Checksum(test.version, test.arch)
+		assert.True(t, errors.Is(err, ErrChecksumNotFound))
+		assert.Nil(t, sum)
+	}
+
+	src := *r.Source
+	src.ChecksumURL = "https://example.org/path/to/SHA256SUMS"
+	sum, err = src.Checksum("1.2.4", "amd64")
+	assert.Nil(t, err)
+	assert.Nil(t, sum)
+
+	sum, err = (&Source{}).Checksum("1.2.3", "amd64")
+	assert.Nil(t, err)
+	assert.Nil(t, sum)
 
 	// Check for "control" section
 	assert.Equal(t, "admin", r.Control.Section)
@@ -32,6 +61,10 @@ func TestRecipeValid(t *testing.T) {
 	assert.Equal(t, []string{"foobar"}, r.Control.Enhances)
 	assert.Equal(t, []string{"foobaz"}, r.Control.Breaks)
 	assert.Equal(t, []string{"foobarbaz"}, r.Control.Conflicts)
+	assert.Equal(t, []string{"barfoo"}, r.Control.Provides)
+	assert.Equal(t, []string{"bazfoo"}, r.Control.Replaces)
+	assert.Equal(t, "foreign", r.Control.MultiArch)
+	assert.Equal(t, map[string]string{"X-Foo": "bar"}, r.Control.Fields)
 	assert.Equal(t, "A long package description providing us with information on the upstream software.",
 		r.Control.Description)
 
@@ -65,6 +98,12 @@ func TestRecipeValid(t *testing.T) {
 	recipeFiles = append(recipeFiles, File{"testdata/valid/files/init", fi})
 	assert.Equal(t, recipeFiles, r.RecipeFiles)
 
+	keyFiles := []File{}
+	fi, err = os.Stat("testdata/valid/keys/foo.asc")
+	assert.Nil(t, err)
+	keyFiles = append(keyFiles, File{"testdata/valid/keys/foo.asc", fi})
+	assert.Equal(t, keyFiles, r.KeyFiles)
+
 	// Check for path matching
 	path, confFile, ok := r.InstallPath("init", r.Install.Recipe)
 	assert.Equal(t, "/etc/init.d/foo", path)
@@ -76,6 +115,18 @@ func TestRecipeValid(t *testing.T) {
 	path, confFile, ok = r.InstallPath("bar", r.Install.Upstream)
 	assert.Equal(t, "", path)
 	assert.False(t, confFile, ok)
+
+	match, ok := r.MatchInstall("init", r.Install.Recipe)
+	assert.True(t, ok)
+	assert.Equal(t, &InstallMatch{
+		Base:  "/etc/init.d",
+		Index: 0,
+		Rule:  InstallRule{Pattern: "init", Rename: "foo", ConfFile: true},
+		Path:  "/etc/init.d/foo",
+	}, match)
+	match, ok = r.MatchInstall("bar", r.Install.Upstream)
+	assert.Nil(t, match)
+	assert.False(t, ok)
 }
 
 func TestRecipeUnsupportedVersion(t *testing.T) {
@@ -85,6 +136,46 @@ func TestRecipeUnsupportedVersion(t *testing.T) {
 	assert.Equal(t, ErrUnsupportedVersion, r.Validate())
 }
 
+func TestRecipePosition(t *testing.T) {
+	r, err := LoadRecipe("testdata/valid")
+	assert.Nil(t, err)
+
+	for _, test := range []struct {
+		path         []string
+		line, column int
+	}{
+		{[]string{"name"}, 4, 7},
+		{[]string{"control", "pre-depends", "0"}, 29, 5},
+		{[]string{"install", "upstream"}, 55, 3},
+		{[]string{"install", "upstream", "/usr/bin", "0", "pattern"}, 57, 16},
+		{[]string{"links", "/path/to/link"}, 63, 18},
+		{[]string{"dirs", "1"}, 59, 1},
+		{[]string{"unknown", "field"}, 2, 1},
+	} {
+		line, column := r.Position(test.path...)
+		assert.Equal(t, test.line, line, "path: %q", test.path)
+		assert.Equal(t, test.column, column, "path: %q", test.path)
+	}
+
+	line, column := (&Recipe{}).Position("name")
+	assert.Equal(t, 0, line)
+	assert.Equal(t, 0, column)
+}
+
+func TestRecipeUnknownField(t *testing.T) {
+	r, err := LoadRecipe("testdata/unknown-field")
+	assert.NotNil(t, r)
+	assert.Nil(t, err)
+
+	expected := FieldErrors{{Field: "control.pre-depend", Line: 13, Column: 3, Err: ErrUnknownField}}
+	assert.Equal(t, expected, r.FieldErrors)
+	assert.Equal(t, expected, r.Validate())
+
+	// Unknown fields are ignored with format version 1
+	r.Version = 1
+	assert.Nil(t, r.Validate())
+}
+
 func TestRecipeMissingName(t *testing.T) {
 	r, err := LoadRecipe("testdata/missing-name")
 	assert.NotNil(t, r)
@@ -127,9 +218,73 @@ func TestRecipeMissingControlDescription(t *testing.T) {
 	assert.Equal(t, ErrMissingControlDescription, r.Validate())
 }
 
+func TestRecipeInvalidControlField(t *testing.T) {
+	r, err := LoadRecipe("testdata/valid")
+	assert.NotNil(t, r)
+	assert.Nil(t, err)
+
+	r.Control.Fields["Foo"] = "bar"
+	assert.True(t, errors.Is(r.Validate(), ErrInvalidControlField))
+}
+
 func TestRecipeMissingInstall(t *testing.T) {
 	r, err := LoadRecipe("testdata/missing-install")
 	assert.NotNil(t, r)
 	assert.Nil(t, err)
 	assert.Equal(t, ErrMissingInstall, r.Validate())
 }
+
+func TestRecipeArchOverrides(t *testing.T) {
+	r, err := LoadRecipe("testdata/arch-overrides")
+	assert.Nil(t, err)
+	assert.Len(t, r.ArchOverrides, 2)
+
+	// Check for non-overridden architecture
+	assert.Equal(t, r, r.Arch("amd64"))
+
+	// Check for overridden architectures
+	ar := r.Arch("arm64")
+	assert.NotEqual(t, r, ar)
+	assert.Nil(t, ar.ArchOverrides)
+	assert.Equal(t, "https://example.org/path/to/foo-{{ .Version }}-{{ .Arch }}.zip", ar.Source.URL)
+	assert.Equal(t, "archive", ar.Source.Type)
+	assert.Equal(t, 0, ar.Source.Strip)
+	assert.Equal(t, map[string]string{"amd64": "x86_64", "arm64": "aarch64", "armhf": "armv7"},
+		ar.Source.ArchMapping)
+	assert.Equal(t, []string{"bar", "libatomic1"}, ar.Control.Depends)
+	assert.Equal(t, "A long package description providing us with information on the upstream software.",
+		ar.Control.Description)
+	assert.Equal(t, InstallMap{
+		"/usr/bin":           []InstallRule{{Pattern: "bin/foo"}},
+		"/usr/share/doc/foo": []InstallRule{{Pattern: "README"}},
+	}, ar.Install.Upstream)
+
+	ar = r.Arch("armhf")
+	assert.Equal(t, "https://example.org/path/to/foo-{{ .Version }}.{{ .Arch }}.tar.gz", ar.Source.URL)
+	assert.Equal(t, "file", ar.Source.Type)
+	assert.Equal(t, 1, ar.Source.Strip)
+
+	// Ensure base recipe has been left untouched
+	assert.Equal(t, "https://example.org/path/to/foo-{{ .Version }}.{{ .Arch }}.tar.gz", r.Source.URL)
+	assert.Equal(t, 1, r.Source.Strip)
+	assert.Equal(t, []string{"bar"}, r.Control.Depends)
+	assert.Equal(t, InstallMap{
+		"/usr/bin":           []InstallRule{{Pattern: "foo"}},
+		"/usr/share/doc/foo": []InstallRule{{Pattern: "README"}},
+	}, r.Install.Upstream)
+}
+
+func TestInstallRuleMatch(t *testing.T) {
+	for _, test := range []struct {
+		rule     InstallRule
+		path     string
+		expected bool
+	}{
+		{InstallRule{Pattern: "foo*"}, "foo.conf", true},
+		{InstallRule{Pattern: "foo*", Exclude: "*.conf"}, "foo.conf", false},
+		{InstallRule{Pattern: "*"}, "path/to/foo", true},
+		{InstallRule{Pattern: "bar"}, "foo", false},
+	} {
+		assert.Equal(t, test.expected, test.rule.Match(test.path))
+	}
+}
@@ -0,0 +1,249 @@
+package recipe
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+//go:generate go run internal/schema/main.go -o schema.json
+
+const schemaDraft = "http://json-schema.org/draft-07/schema#"
+
+var recipeSchema = NewSchema()
+
+// Schema is a JSON Schema document describing recipes.
+type Schema struct {
+	Draft                string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Maximum              *int               `json:"maximum,omitempty"`
+}
+
+// NewSchema generates the recipe JSON Schema from the recipe types definitions.
+func NewSchema() *Schema {
+	s := typeSchema(reflect.TypeOf(Recipe{}))
+	s.Draft = schemaDraft
+	s.Title = "mkdeb recipe"
+
+	// Restrict format version to the supported ones
+	min, max := 1, CurrentVersion
+	s.Properties["version"].Minimum = &min
+	s.Properties["version"].Maximum = &max
+
+	// Architecture overrides only contain the fields to be overridden
+	s.Properties["arch-overrides"].walk(func(s *Schema) { s.Required = nil })
+
+	return s
+}
+
+// Validate checks a YAML document against the schema, returning the unknown or invalid fields found.
+func (s *Schema) Validate(node *yaml.Node) FieldErrors {
+	var errs FieldErrors
+
+	s.validate(node, "", &errs)
+
+	return errs
+}
+
+func (s *Schema) validate(node *yaml.Node, path string, errs *FieldErrors) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, n := range node.Content {
+			s.validate(n, path, errs)
+		}
+		return
+
+	case yaml.AliasNode:
+		s.validate(node.Alias, path, errs)
+		return
+	}
+
+	// Null values are always accepted, as they're decoded as Go zero values
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+
+	invalid := func() {
+		*errs = append(*errs, &FieldError{
+			Field:  path,
+			Line:   node.Line,
+			Column: node.Column,
+			Err:    fmt.Errorf("%w: expected %s", ErrInvalidFieldType, s.Type),
+		})
+	}
+
+	switch s.Type {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			invalid()
+			return
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+
+			// Skip merge keys, their content being validated where anchors are defined
+			if key.Tag == "!!merge" {
+				continue
+			}
+
+			field := key.Value
+			if path != "" {
+				field = path + "." + field
+			}
+
+			if prop, ok := s.Properties[key.Value]; ok {
+				prop.validate(value, field, errs)
+			} else if prop, ok := s.AdditionalProperties.(*Schema); ok {
+				prop.validate(value, field, errs)
+			} else {
+				*errs = append(*errs, &FieldError{
+					Field:  field,
+					Line:   key.Line,
+					Column: key.Column,
+					Err:    ErrUnknownField,
+				})
+			}
+		}
+
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			invalid()
+			return
+		}
+
+		for idx, n := range node.Content {
+			s.Items.validate(n, path+"["+strconv.Itoa(idx)+"]", errs)
+		}
+
+	case "boolean":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			invalid()
+		}
+
+	case "integer":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			invalid()
+		}
+
+	case "string":
+		if node.Kind != yaml.ScalarNode {
+			invalid()
+		}
+	}
+}
+
+func (s *Schema) walk(f func(*Schema)) {
+	if s == nil {
+		return
+	}
+
+	f(s)
+
+	for _, prop := range s.Properties {
+		prop.walk(f)
+	}
+
+	if prop, ok := s.AdditionalProperties.(*Schema); ok {
+		prop.walk(f)
+	}
+
+	s.Items.walk(f)
+}
+
+func typeSchema(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		s := &Schema{
+			Type:                 "object",
+			Properties:           map[string]*Schema{},
+			AdditionalProperties: false,
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+
+			tag := f.Tag.Get("yaml")
+			if f.PkgPath != "" || tag == "" || tag == "-" {
+				continue
+			}
+
+			name := strings.Split(tag, ",")[0]
+			s.Properties[name] = typeSchema(f.Type)
+
+			if f.Tag.Get("schema") == "required" {
+				s.Required = append(s.Required, name)
+			}
+		}
+
+		return s
+
+	case reflect.Map:
+		return &Schema{
+			Type:                 "object",
+			AdditionalProperties: typeSchema(t.Elem()),
+		}
+
+	case reflect.Slice:
+		return &Schema{
+			Type:  "array",
+			Items: typeSchema(t.Elem()),
+		}
+
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		min := 0
+		return &Schema{Type: "integer", Minimum: &min}
+
+	case reflect.String:
+		return &Schema{Type: "string"}
+	}
+
+	panic(fmt.Sprintf("unsupported %q schema type", t))
+}
+
+// FieldError is a recipe field error, referencing the field position in the recipe file.
+type FieldError struct {
+	Field  string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %q: %s", e.Line, e.Column, e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors is a list of recipe field errors.
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	s := make([]string, len(e))
+	for idx, err := range e {
+		s[idx] = err.Error()
+	}
+
+	return strings.Join(s, "; ")
+}
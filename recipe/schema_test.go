@@ -0,0 +1,66 @@
+package recipe
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestSchemaGenerated(t *testing.T) {
+	data, err := ioutil.ReadFile("schema.json")
+	assert.Nil(t, err)
+
+	expected, err := json.MarshalIndent(NewSchema(), "", "  ")
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected)+"\n", string(data), `schema is outdated, please run "go generate"`)
+}
+
+func TestSchemaValidate(t *testing.T) {
+	var node yaml.Node
+
+	assert.Nil(t, yaml.Unmarshal([]byte(`version: 2
+name: foo
+source:
+  url: https://example.org/foo.tar.gz
+  strip: one
+  arch-mapping:
+    amd64: x86_64
+control:
+  pre-depend:
+  - bar
+  essential: true
+install:
+  upstream:
+    /usr/bin:
+    - pattern: foo
+      conffiel: true
+arch-overrides:
+  arm64:
+    source:
+      url: https://example.org/foo-arm64.tar.gz
+    name: bar
+`), &node))
+
+	errs := NewSchema().Validate(&node)
+	assert.Len(t, errs, 4)
+
+	for idx, expected := range []struct {
+		field        string
+		line, column int
+		err          error
+	}{
+		{"source.strip", 5, 10, ErrInvalidFieldType},
+		{"control.pre-depend", 9, 3, ErrUnknownField},
+		{"install.upstream./usr/bin[0].conffiel", 16, 7, ErrUnknownField},
+		{"arch-overrides.arm64.name", 21, 5, ErrUnknownField},
+	} {
+		assert.Equal(t, expected.field, errs[idx].Field)
+		assert.Equal(t, expected.line, errs[idx].Line, "field: %s", expected.field)
+		assert.Equal(t, expected.column, errs[idx].Column, "field: %s", expected.field)
+		assert.True(t, errors.Is(errs[idx], expected.err), "field: %s", expected.field)
+	}
+}
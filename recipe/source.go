@@ -1,9 +1,45 @@
 package recipe
 
+import "fmt"
+
 // Source is a recipe source.
 type Source struct {
-	URL         string            `yaml:"url"`
-	Type        string            `yaml:"type"`
-	Strip       int               `yaml:"strip"`
-	ArchMapping map[string]string `yaml:"arch-mapping"`
+	URL          string                       `yaml:"url" schema:"required"`
+	Type         string                       `yaml:"type"`
+	Strip        int                          `yaml:"strip"`
+	ArchMapping  map[string]string            `yaml:"arch-mapping"`
+	Checksums    map[string]map[string]string `yaml:"checksums"`
+	ChecksumURL  string                       `yaml:"checksum-url"`
+	SignatureURL string                       `yaml:"signature-url"`
+	Latest       *Latest                      `yaml:"latest"`
+}
+
+// Latest is a recipe source latest version discovery configuration.
+//
+// Type is either "github" (using the GitHub releases API for Repository), "html" (matching Pattern against the
+// page content found at URL) or "json" (looking up Path in the document found at URL). When set, Pattern first
+// capture group is used to extract the version from the discovered values.
+type Latest struct {
+	Type       string `yaml:"type"`
+	Repository string `yaml:"repository"`
+	URL        string `yaml:"url"`
+	Pattern    string `yaml:"pattern"`
+	Path       string `yaml:"path"`
+}
+
+// Checksum returns the upstream archive checksum declared for a given version and architecture.
+//
+// A nil checksum is returned if none has been declared in the recipe, either because it declares no checksums at all
+// or because it provides a checksums listing URL to fall back on. ErrChecksumNotFound is returned otherwise.
+func (s *Source) Checksum(version, arch string) (*Checksum, error) {
+	v, ok := s.Checksums[version][arch]
+	if !ok {
+		if len(s.Checksums) > 0 && s.ChecksumURL == "" {
+			return nil, fmt.Errorf("%w: version %q, architecture %q", ErrChecksumNotFound, version, arch)
+		}
+
+		return nil, nil
+	}
+
+	return ParseChecksum(v)
 }
@@ -0,0 +1,139 @@
+package recipe
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// TemplateData is the data made available to recipe templates.
+//
+// Arch is an alias for UpstreamArch, kept for compatibility with existing source URL templates.
+type TemplateData struct {
+	Name         string
+	Version      string
+	Epoch        uint
+	Arch         string
+	DebArch      string
+	UpstreamArch string
+}
+
+// Render executes a recipe template given its data.
+func Render(text string, data *TemplateData) (string, error) {
+	buf := bytes.NewBuffer(nil)
+
+	tmpl, err := template.New("").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse template: %w", err)
+	} else if err = tmpl.Execute(buf, data); err != nil {
+		return "", fmt.Errorf("cannot execute template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// RenderTemplates renders recipe installation rules, directories, symbolic links and control relations templates
+// in place.
+func (r *Recipe) RenderTemplates(data *TemplateData) error {
+	var err error
+
+	if r.Install != nil {
+		r.Install.Recipe, err = renderInstallMap(r.Install.Recipe, data)
+		if err != nil {
+			return err
+		}
+
+		r.Install.Upstream, err = renderInstallMap(r.Install.Upstream, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = renderSlice(r.Dirs, data)
+	if err != nil {
+		return err
+	}
+
+	if r.Links != nil {
+		links := make(map[string]string, len(r.Links))
+		for dst, src := range r.Links {
+			dst, err = Render(dst, data)
+			if err != nil {
+				return err
+			}
+
+			links[dst], err = Render(src, data)
+			if err != nil {
+				return err
+			}
+		}
+		r.Links = links
+	}
+
+	if r.Control != nil {
+		for _, v := range [][]string{
+			r.Control.Depends,
+			r.Control.PreDepends,
+			r.Control.Recommends,
+			r.Control.Suggests,
+			r.Control.Enhances,
+			r.Control.Breaks,
+			r.Control.Conflicts,
+			r.Control.Provides,
+			r.Control.Replaces,
+			r.Control.BuiltUsing,
+		} {
+			err = renderSlice(v, data)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func renderInstallMap(m InstallMap, data *TemplateData) (InstallMap, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	result := make(InstallMap, len(m))
+
+	for dst, rules := range m {
+		dst, err := Render(dst, data)
+		if err != nil {
+			return nil, err
+		}
+
+		var rendered []InstallRule
+
+		for _, rule := range rules {
+			for _, v := range []*string{&rule.Pattern, &rule.Exclude, &rule.Rename} {
+				*v, err = Render(*v, data)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			rendered = append(rendered, rule)
+		}
+
+		result[dst] = append(result[dst], rendered...)
+	}
+
+	return result, nil
+}
+
+func renderSlice(s []string, data *TemplateData) error {
+	var err error
+
+	for idx := range s {
+		s[idx], err = Render(s[idx], data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
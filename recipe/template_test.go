@@ -0,0 +1,61 @@
+package recipe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testTemplateData = &TemplateData{
+	Name:         "foo",
+	Version:      "1.2.3",
+	Epoch:        1,
+	Arch:         "x86_64",
+	DebArch:      "amd64",
+	UpstreamArch: "x86_64",
+}
+
+func TestRender(t *testing.T) {
+	v, err := Render("{{ .Name }}-{{ .Version }}_{{ .Epoch }}_{{ .DebArch }}_{{ .UpstreamArch }}_{{ .Arch }}",
+		testTemplateData)
+	assert.Nil(t, err)
+	assert.Equal(t, "foo-1.2.3_1_amd64_x86_64_x86_64", v)
+
+	_, err = Render("{{ .Version", testTemplateData)
+	assert.NotNil(t, err)
+
+	_, err = Render("{{ .Unknown }}", testTemplateData)
+	assert.NotNil(t, err)
+}
+
+func TestRecipeRenderTemplates(t *testing.T) {
+	r := &Recipe{
+		Control: &Control{
+			Depends:  []string{"libfoo (= {{ .Version }})"},
+			Provides: []string{"{{ .Name }}-{{ .DebArch }}"},
+		},
+		Install: &Install{
+			Upstream: InstallMap{
+				"/usr/bin": []InstallRule{{Pattern: "foo-{{ .Version }}/bin/foo-{{ .Version }}", Rename: "foo"}},
+				"/usr/share/{{ .Name }}": []InstallRule{{Pattern: "foo-{{ .Version }}/*",
+					Exclude: "foo-{{ .Version }}/bin"}},
+			},
+		},
+		Dirs:  []string{"/var/lib/{{ .Name }}"},
+		Links: map[string]string{"/usr/bin/{{ .Name }}-{{ .Version }}": "/usr/bin/{{ .Name }}"},
+	}
+
+	assert.Nil(t, r.RenderTemplates(testTemplateData))
+	assert.Equal(t, []string{"libfoo (= 1.2.3)"}, r.Control.Depends)
+	assert.Equal(t, []string{"foo-amd64"}, r.Control.Provides)
+	assert.Equal(t, InstallMap{
+		"/usr/bin":       []InstallRule{{Pattern: "foo-1.2.3/bin/foo-1.2.3", Rename: "foo"}},
+		"/usr/share/foo": []InstallRule{{Pattern: "foo-1.2.3/*", Exclude: "foo-1.2.3/bin"}},
+	}, r.Install.Upstream)
+	assert.Nil(t, r.Install.Recipe)
+	assert.Equal(t, []string{"/var/lib/foo"}, r.Dirs)
+	assert.Equal(t, map[string]string{"/usr/bin/foo-1.2.3": "/usr/bin/foo"}, r.Links)
+
+	r = &Recipe{Dirs: []string{"/var/lib/{{ .Foo }}"}}
+	assert.NotNil(t, r.RenderTemplates(testTemplateData))
+}
@@ -1,6 +1,11 @@
 package recipe
 
+// CurrentVersion is the current recipe format version.
+//
+// Version 2 recipes are decoded strictly: unknown fields are rejected instead of being silently ignored.
+const CurrentVersion = 2
+
 // VersionSupported returns whether or not a recipe version is supported.
 func VersionSupported(version int) bool {
-	return version == 1
+	return version >= 1 && version <= CurrentVersion
 }
@@ -0,0 +1,18 @@
+package upstream
+
+import "errors"
+
+var (
+	// ErrInvalidKey is an invalid key error.
+	ErrInvalidKey = errors.New("invalid key")
+	// ErrInvalidSignature is an invalid signature error.
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrNoKeys is a missing trusted keys error.
+	ErrNoKeys = errors.New("no trusted keys")
+	// ErrUnknownKey is an unknown signing key error.
+	ErrUnknownKey = errors.New("unknown signing key")
+	// ErrUnsupportedLatest is an unsupported latest version discovery type error.
+	ErrUnsupportedLatest = errors.New("unsupported latest version type")
+	// ErrVersionNotFound is a latest version not found error.
+	ErrVersionNotFound = errors.New("version not found")
+)
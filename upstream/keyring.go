@@ -0,0 +1,98 @@
+package upstream
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/openpgp"
+	"mkdeb.sh/recipe"
+)
+
+// Keyring is a set of keys trusted to sign upstream archives.
+type Keyring struct {
+	pgpKeys      openpgp.EntityList
+	minisignKeys []*minisignKey
+}
+
+// LoadKeyring loads a keyring given a list of recipe key files.
+//
+// OpenPGP keys are read from ".asc", ".gpg" and ".pgp" files (either armored or binary), and minisign public keys
+// from ".pub" files. Other files are ignored.
+func LoadKeyring(files []recipe.File) (*Keyring, error) {
+	k := &Keyring{}
+
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		switch filepath.Ext(f.Path) {
+		case ".asc", ".gpg", ".pgp":
+			var list openpgp.EntityList
+
+			if isArmored(data) {
+				list, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+			} else {
+				list, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+			}
+			if err != nil {
+				return nil, fmt.Errorf("cannot read %q key: %w", f.FileInfo.Name(), err)
+			}
+
+			k.pgpKeys = append(k.pgpKeys, list...)
+
+		case ".pub":
+			key, err := parseMinisignKey(data)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read %q key: %w", f.FileInfo.Name(), err)
+			}
+
+			k.minisignKeys = append(k.minisignKeys, key)
+		}
+	}
+
+	return k, nil
+}
+
+// Len returns the number of keys in the keyring.
+func (k *Keyring) Len() int {
+	return len(k.pgpKeys) + len(k.minisignKeys)
+}
+
+// Verify checks a file against a detached signature issued by one of the keyring keys.
+//
+// Supported signatures are OpenPGP ones (either armored or binary) and minisign ones.
+func (k *Keyring) Verify(path string, sig []byte) error {
+	if k.Len() == 0 {
+		return ErrNoKeys
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if isMinisign(sig) {
+		return k.verifyMinisign(f, sig)
+	}
+
+	if isArmored(sig) {
+		_, err = openpgp.CheckArmoredDetachedSignature(k.pgpKeys, f, bytes.NewReader(sig))
+	} else {
+		_, err = openpgp.CheckDetachedSignature(k.pgpKeys, f, bytes.NewReader(sig))
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSignature, err)
+	}
+
+	return nil
+}
+
+func isArmored(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN "))
+}
@@ -0,0 +1,194 @@
+package upstream
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"mkdeb.sh/recipe"
+)
+
+var testData = []byte("upstream archive content\n")
+
+func TestKeyringOpenPGP(t *testing.T) {
+	entity, err := openpgp.NewEntity("Foo Bar", "", "foo@example.org", nil)
+	assert.Nil(t, err)
+
+	// Export public key as armored key file
+	key := bytes.NewBuffer(nil)
+	w, err := armor.Encode(key, openpgp.PublicKeyType, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, entity.Serialize(w))
+	assert.Nil(t, w.Close())
+
+	armored := bytes.NewBuffer(nil)
+	assert.Nil(t, openpgp.ArmoredDetachSign(armored, entity, bytes.NewReader(testData), nil))
+
+	binary := bytes.NewBuffer(nil)
+	assert.Nil(t, openpgp.DetachSign(binary, entity, bytes.NewReader(testData), nil))
+
+	dir := testKeysDir(t, map[string][]byte{"foo.asc": key.Bytes(), "README": []byte("ignored")})
+	defer os.RemoveAll(dir)
+
+	srv := testServer(map[string][]byte{
+		"/foo.tar.gz":     testData,
+		"/foo.tar.gz.asc": armored.Bytes(),
+		"/foo.tar.gz.sig": binary.Bytes(),
+		"/bar.tar.gz":     []byte("altered content\n"),
+	})
+	defer srv.Close()
+
+	k := testKeyring(t, dir)
+	assert.Equal(t, 1, k.Len())
+
+	path := testDownload(t, dir, srv.URL+"/foo.tar.gz")
+
+	sig, err := Fetch(srv.URL + "/foo.tar.gz.asc")
+	assert.Nil(t, err)
+	assert.Nil(t, k.Verify(path, sig))
+
+	sig, err = Fetch(srv.URL + "/foo.tar.gz.sig")
+	assert.Nil(t, err)
+	assert.Nil(t, k.Verify(path, sig))
+
+	path = testDownload(t, dir, srv.URL+"/bar.tar.gz")
+	assert.True(t, errors.Is(k.Verify(path, sig), ErrInvalidSignature))
+
+	_, err = Fetch(srv.URL + "/baz.tar.gz.asc")
+	assert.NotNil(t, err)
+}
+
+func TestKeyringMinisign(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+
+	id := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	key := "untrusted comment: minisign public key\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), id...), pub...)) + "\n"
+
+	dir := testKeysDir(t, map[string][]byte{"foo.pub": []byte(key)})
+	defer os.RemoveAll(dir)
+
+	srv := testServer(map[string][]byte{
+		"/foo.tar.gz":              testData,
+		"/foo.tar.gz.minisig":      testMinisign(priv, id, "ED", "timestamp:1234", ""),
+		"/foo.tar.gz.legacy":       testMinisign(priv, id, "Ed", "timestamp:1234", ""),
+		"/foo.tar.gz.comment":      testMinisign(priv, id, "ED", "timestamp:1234", "timestamp:5678"),
+		"/foo.tar.gz.unknown-key":  testMinisign(priv, []byte{8, 7, 6, 5, 4, 3, 2, 1}, "ED", "", ""),
+		"/foo.tar.gz.invalid-data": []byte("untrusted comment: foo\ninvalid\n"),
+	})
+	defer srv.Close()
+
+	k := testKeyring(t, dir)
+	assert.Equal(t, 1, k.Len())
+
+	path := testDownload(t, dir, srv.URL+"/foo.tar.gz")
+
+	for _, test := range []struct {
+		sig string
+		err error
+	}{
+		{sig: "minisig"},
+		{sig: "legacy"},
+		{sig: "comment", err: ErrInvalidSignature},
+		{sig: "unknown-key", err: ErrUnknownKey},
+		{sig: "invalid-data", err: ErrInvalidSignature},
+	} {
+		sig, err := Fetch(srv.URL + "/foo.tar.gz." + test.sig)
+		assert.Nil(t, err)
+
+		err = k.Verify(path, sig)
+		if test.err == nil {
+			assert.Nil(t, err, "signature: %s", test.sig)
+		} else {
+			assert.True(t, errors.Is(err, test.err), "signature: %s", test.sig)
+		}
+	}
+}
+
+func TestKeyringEmpty(t *testing.T) {
+	k, err := LoadKeyring(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, ErrNoKeys, k.Verify("testdata/none", nil))
+}
+
+func testKeysDir(t *testing.T, files map[string][]byte) string {
+	dir, err := ioutil.TempDir("", "mkdeb-upstream")
+	assert.Nil(t, err)
+
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "keys"), 0755))
+	for name, data := range files {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "keys", name), data, 0644))
+	}
+
+	return dir
+}
+
+func testKeyring(t *testing.T, dir string) *Keyring {
+	var files []recipe.File
+
+	infos, err := ioutil.ReadDir(filepath.Join(dir, "keys"))
+	assert.Nil(t, err)
+
+	for _, fi := range infos {
+		files = append(files, recipe.File{Path: filepath.Join(dir, "keys", fi.Name()), FileInfo: fi})
+	}
+
+	k, err := LoadKeyring(files)
+	assert.Nil(t, err)
+
+	return k
+}
+
+func testDownload(t *testing.T, dir, url string) string {
+	data, err := Fetch(url)
+	assert.Nil(t, err)
+
+	path := filepath.Join(dir, filepath.Base(url))
+	assert.Nil(t, ioutil.WriteFile(path, data, 0644))
+
+	return path
+}
+
+func testMinisign(priv ed25519.PrivateKey, id []byte, alg, trusted, altered string) []byte {
+	msg := testData
+	if alg == "ED" {
+		sum := blake2b.Sum512(testData)
+		msg = sum[:]
+	}
+
+	sig := ed25519.Sign(priv, msg)
+	global := ed25519.Sign(priv, append(append([]byte{}, sig...), trusted...))
+
+	if altered != "" {
+		trusted = altered
+	}
+
+	return []byte("untrusted comment: signature\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte(alg), id...), sig...)) + "\n" +
+		"trusted comment: " + trusted + "\n" +
+		base64.StdEncoding.EncodeToString(global) + "\n")
+}
+
+func testServer(files map[string][]byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+}
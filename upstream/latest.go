@@ -0,0 +1,223 @@
+package upstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"mkdeb.sh/deb"
+	"mkdeb.sh/recipe"
+)
+
+// GitHubAPIURL is the base URL of the GitHub API used to retrieve latest releases.
+var GitHubAPIURL = "https://api.github.com"
+
+const defaultGitHubPattern = `^v?(\d.*)$`
+
+// LatestVersion discovers the latest upstream version given a recipe source latest version configuration.
+//
+// When multiple candidates are found, the greatest one according to Debian versions comparison is returned.
+func LatestVersion(l *recipe.Latest) (string, error) {
+	var (
+		candidates []string
+		pattern    = l.Pattern
+		err        error
+	)
+
+	switch l.Type {
+	case "github":
+		if pattern == "" {
+			pattern = defaultGitHubPattern
+		}
+		candidates, err = latestGitHub(l.Repository)
+
+	case "html":
+		candidates, err = latestHTML(l.URL, pattern)
+		pattern = ""
+
+	case "json":
+		candidates, err = latestJSON(l.URL, l.Path)
+
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedLatest, l.Type)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if pattern != "" {
+		candidates, err = matchVersions(candidates, pattern)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return greatestVersion(candidates)
+}
+
+func latestGitHub(repository string) ([]string, error) {
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+
+	if strings.Count(repository, "/") != 1 {
+		return nil, fmt.Errorf("invalid GitHub repository %q", repository)
+	}
+
+	data, err := fetchAPI(strings.TrimSuffix(GitHubAPIURL, "/") + "/repos/" + repository + "/releases/latest")
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &release)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode GitHub release: %w", err)
+	}
+
+	return []string{release.TagName}, nil
+}
+
+func latestHTML(url, pattern string) ([]string, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("missing pattern for %q", url)
+	}
+
+	data, err := Fetch(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return matchVersions([]string{string(data)}, pattern)
+}
+
+func latestJSON(url, path string) ([]string, error) {
+	var doc interface{}
+
+	data, err := Fetch(url)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode %q: %w", url, err)
+	}
+
+	values := []interface{}{doc}
+	if path != "" {
+		for _, key := range strings.Split(path, ".") {
+			values = lookupJSON(values, key)
+		}
+	}
+
+	candidates := []string{}
+	for _, v := range values {
+		switch v := v.(type) {
+		case string:
+			candidates = append(candidates, v)
+		case float64:
+			candidates = append(candidates, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+	}
+
+	return candidates, nil
+}
+
+// lookupJSON resolves a path key against a set of JSON values. Key is either an object key, an array index or
+// "*" to select all the array items or object values.
+func lookupJSON(values []interface{}, key string) []interface{} {
+	result := []interface{}{}
+
+	for _, value := range values {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if key == "*" {
+				for _, item := range v {
+					result = append(result, item)
+				}
+			} else if item, ok := v[key]; ok {
+				result = append(result, item)
+			}
+
+		case []interface{}:
+			if key == "*" {
+				result = append(result, v...)
+			} else if idx, err := strconv.Atoi(key); err == nil && idx >= 0 && idx < len(v) {
+				result = append(result, v[idx])
+			}
+		}
+	}
+
+	return result
+}
+
+func fetchAPI(url string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("cannot fetch %q: %s", url, resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+func matchVersions(values []string, pattern string) ([]string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compile pattern: %w", err)
+	} else if re.NumSubexp() < 1 {
+		return nil, fmt.Errorf("pattern %q has no capture group", pattern)
+	}
+
+	result := []string{}
+	for _, value := range values {
+		for _, match := range re.FindAllStringSubmatch(value, -1) {
+			result = append(result, match[1])
+		}
+	}
+
+	return result, nil
+}
+
+func greatestVersion(candidates []string) (string, error) {
+	var (
+		latest  string
+		version *deb.Version
+	)
+
+	for _, candidate := range candidates {
+		v, err := deb.ParseVersion(candidate)
+		if err != nil {
+			continue
+		}
+
+		if version == nil || deb.Compare(v, version) > 0 {
+			latest, version = candidate, v
+		}
+	}
+
+	if version == nil {
+		return "", ErrVersionNotFound
+	}
+
+	return latest, nil
+}
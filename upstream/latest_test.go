@@ -0,0 +1,110 @@
+package upstream
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"mkdeb.sh/recipe"
+)
+
+func TestLatestVersionGitHub(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/foo/bar/releases/latest":
+			fmt.Fprint(rw, `{"tag_name": "v1.2.3", "name": "Release 1.2.3"}`)
+		case "/repos/foo/baz/releases/latest":
+			fmt.Fprint(rw, `{"tag_name": "baz-2.0.0"}`)
+		default:
+			http.NotFound(rw, r)
+		}
+	}))
+	defer srv.Close()
+
+	apiURL := GitHubAPIURL
+	GitHubAPIURL = srv.URL
+	defer func() { GitHubAPIURL = apiURL }()
+
+	version, err := LatestVersion(&recipe.Latest{Type: "github", Repository: "foo/bar"})
+	assert.Nil(t, err)
+	assert.Equal(t, "1.2.3", version)
+
+	version, err = LatestVersion(&recipe.Latest{Type: "github", Repository: "foo/baz", Pattern: `^baz-(.+)$`})
+	assert.Nil(t, err)
+	assert.Equal(t, "2.0.0", version)
+
+	_, err = LatestVersion(&recipe.Latest{Type: "github", Repository: "foo/baz"})
+	assert.True(t, errors.Is(err, ErrVersionNotFound))
+
+	_, err = LatestVersion(&recipe.Latest{Type: "github", Repository: "foo/unknown"})
+	assert.NotNil(t, err)
+
+	_, err = LatestVersion(&recipe.Latest{Type: "github", Repository: "foo"})
+	assert.NotNil(t, err)
+}
+
+func TestLatestVersionHTML(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, `<html><body>
+<a href="foo-1.9.0.tar.gz">foo-1.9.0.tar.gz</a>
+<a href="foo-1.10.0.tar.gz">foo-1.10.0.tar.gz</a>
+<a href="foo-1.10.0~rc1.tar.gz">foo-1.10.0~rc1.tar.gz</a>
+<a href="foo-1.2.0.tar.gz">foo-1.2.0.tar.gz</a>
+</body></html>`)
+	}))
+	defer srv.Close()
+
+	version, err := LatestVersion(&recipe.Latest{Type: "html", URL: srv.URL, Pattern: `href="foo-([^"]+)\.tar\.gz"`})
+	assert.Nil(t, err)
+	assert.Equal(t, "1.10.0", version)
+
+	_, err = LatestVersion(&recipe.Latest{Type: "html", URL: srv.URL, Pattern: `href="bar-([^"]+)\.tar\.gz"`})
+	assert.True(t, errors.Is(err, ErrVersionNotFound))
+
+	_, err = LatestVersion(&recipe.Latest{Type: "html", URL: srv.URL, Pattern: `foo-[0-9.]+`})
+	assert.NotNil(t, err)
+
+	_, err = LatestVersion(&recipe.Latest{Type: "html", URL: srv.URL})
+	assert.NotNil(t, err)
+}
+
+func TestLatestVersionJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/current":
+			fmt.Fprint(rw, `{"current": {"version": "v3.4.5"}}`)
+		case "/releases":
+			fmt.Fprint(rw, `{"releases": [{"version": "2.0.1"}, {"version": "2.0.10"}, {"version": "2.0.2"}]}`)
+		default:
+			http.NotFound(rw, r)
+		}
+	}))
+	defer srv.Close()
+
+	for _, test := range []struct {
+		latest   *recipe.Latest
+		expected string
+	}{
+		{&recipe.Latest{Type: "json", URL: srv.URL + "/current", Path: "current.version", Pattern: `^v(.+)$`}, "3.4.5"},
+		{&recipe.Latest{Type: "json", URL: srv.URL + "/releases", Path: "releases.*.version"}, "2.0.10"},
+		{&recipe.Latest{Type: "json", URL: srv.URL + "/releases", Path: "releases.0.version"}, "2.0.1"},
+	} {
+		version, err := LatestVersion(test.latest)
+		assert.Nil(t, err)
+		assert.Equal(t, test.expected, version, "path: %q", test.latest.Path)
+	}
+
+	_, err := LatestVersion(&recipe.Latest{Type: "json", URL: srv.URL + "/releases", Path: "releases.version"})
+	assert.True(t, errors.Is(err, ErrVersionNotFound))
+
+	_, err = LatestVersion(&recipe.Latest{Type: "json", URL: srv.URL + "/unknown", Path: "version"})
+	assert.NotNil(t, err)
+}
+
+func TestLatestVersionUnsupported(t *testing.T) {
+	_, err := LatestVersion(&recipe.Latest{Type: "foo"})
+	assert.True(t, errors.Is(err, ErrUnsupportedLatest))
+}
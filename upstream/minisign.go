@@ -0,0 +1,127 @@
+package upstream
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	minisignAlgLegacy   = "Ed"
+	minisignAlgPrehash  = "ED"
+	minisignKeyIDSize   = 8
+	minisignCommentLine = "untrusted comment:"
+	minisignTrustedLine = "trusted comment: "
+)
+
+type minisignKey struct {
+	id  []byte
+	key ed25519.PublicKey
+}
+
+func parseMinisignKey(data []byte) (*minisignKey, error) {
+	lines := minisignLines(data)
+	if len(lines) != 1 {
+		return nil, ErrInvalidKey
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[0]))
+	if err != nil || len(raw) != 2+minisignKeyIDSize+ed25519.PublicKeySize || string(raw[:2]) != minisignAlgLegacy {
+		return nil, ErrInvalidKey
+	}
+
+	return &minisignKey{
+		id:  raw[2 : 2+minisignKeyIDSize],
+		key: ed25519.PublicKey(raw[2+minisignKeyIDSize:]),
+	}, nil
+}
+
+func (k *Keyring) verifyMinisign(r io.Reader, sig []byte) error {
+	lines := minisignLines(sig)
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], minisignTrustedLine) {
+		return ErrInvalidSignature
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[0]))
+	if err != nil || len(raw) != 2+minisignKeyIDSize+ed25519.SignatureSize {
+		return ErrInvalidSignature
+	}
+
+	alg, id, signature := string(raw[:2]), raw[2:2+minisignKeyIDSize], raw[2+minisignKeyIDSize:]
+
+	global, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[2]))
+	if err != nil || len(global) != ed25519.SignatureSize {
+		return ErrInvalidSignature
+	}
+
+	var key *minisignKey
+	for _, mk := range k.minisignKeys {
+		if bytes.Equal(mk.id, id) {
+			key = mk
+			break
+		}
+	}
+	if key == nil {
+		return ErrUnknownKey
+	}
+
+	// Compute signed message, prehashed signatures using a BLAKE2b-512 digest of the file content
+	var msg []byte
+
+	switch alg {
+	case minisignAlgLegacy:
+		buf := bytes.NewBuffer(nil)
+		if _, err = io.Copy(buf, r); err != nil {
+			return err
+		}
+		msg = buf.Bytes()
+
+	case minisignAlgPrehash:
+		h, _ := blake2b.New512(nil)
+		if _, err = io.Copy(h, r); err != nil {
+			return err
+		}
+		msg = h.Sum(nil)
+
+	default:
+		return fmt.Errorf("%w: unsupported %q algorithm", ErrInvalidSignature, alg)
+	}
+
+	if !ed25519.Verify(key.key, msg, signature) {
+		return ErrInvalidSignature
+	}
+
+	// Check for trusted comment authenticity
+	trusted := strings.TrimPrefix(lines[1], minisignTrustedLine)
+	if !ed25519.Verify(key.key, append(signature, trusted...), global) {
+		return fmt.Errorf("%w: trusted comment mismatch", ErrInvalidSignature)
+	}
+
+	return nil
+}
+
+func isMinisign(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte(minisignCommentLine))
+}
+
+// minisignLines returns the meaningful lines of a minisign file, skipping the untrusted comment.
+func minisignLines(data []byte) []string {
+	var lines []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, minisignCommentLine) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
@@ -0,0 +1,37 @@
+// Package upstream provides helpers to retrieve and authenticate upstream release archives.
+package upstream
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// Get performs a HTTP GET request on a given URL, returning the response body and its content length.
+//
+// Content length will be -1 if unknown. The caller is responsible for closing the returned body.
+func Get(url string) (io.ReadCloser, int64, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if resp.StatusCode >= 400 {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("cannot fetch %q: %s", url, resp.Status)
+	}
+
+	return resp.Body, resp.ContentLength, nil
+}
+
+// Fetch retrieves the content of a given URL.
+func Fetch(url string) ([]byte, error) {
+	body, _, err := Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return ioutil.ReadAll(body)
+}